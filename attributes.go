@@ -0,0 +1,162 @@
+package dynastore
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// AttributeNames the names of the attributes used to store a record in a table, this enables dynastore to
+// use existing tables with a different layout.
+type AttributeNames struct {
+	PartitionKey string
+	SortKey      string
+	Version      string
+	Expires      string
+	Payload      string
+}
+
+// DefaultAttributeNames the attribute names used throughout dynastore
+func DefaultAttributeNames() AttributeNames {
+	return AttributeNames{
+		PartitionKey: DefaultPartitionKeyAttribute,
+		SortKey:      DefaultSortKeyAttribute,
+		Version:      DefaultVersionAttribute,
+		Expires:      DefaultExpiresAttribute,
+		Payload:      DefaultPayloadAttribute,
+	}
+}
+
+// withDefaults assign the default name to any attribute which isn't set
+func (an AttributeNames) withDefaults() AttributeNames {
+	defaults := DefaultAttributeNames()
+
+	if an.PartitionKey == "" {
+		an.PartitionKey = defaults.PartitionKey
+	}
+	if an.SortKey == "" {
+		an.SortKey = defaults.SortKey
+	}
+	if an.Version == "" {
+		an.Version = defaults.Version
+	}
+	if an.Expires == "" {
+		an.Expires = defaults.Expires
+	}
+	if an.Payload == "" {
+		an.Payload = defaults.Payload
+	}
+
+	return an
+}
+
+func (an *AttributeNames) isReserved(s string) bool {
+	switch s {
+	case an.PartitionKey, an.SortKey, an.Version, an.Expires, an.Payload:
+		return true
+	}
+
+	return false
+}
+
+func (an *AttributeNames) isKey(s string) bool {
+	return s == an.PartitionKey || s == an.SortKey
+}
+
+func (an *AttributeNames) buildKeys(partition, key string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		an.PartitionKey: {S: aws.String(partition)},
+		an.SortKey:      {S: aws.String(key)},
+	}
+}
+
+// encodeItem encode a KVPair into a DDB item using the attribute names, this is the inverse of decodeItem
+func (an *AttributeNames) encodeItem(kv *KVPair) map[string]*dynamodb.AttributeValue {
+	item := make(map[string]*dynamodb.AttributeValue, len(kv.fields)+5)
+
+	for k, v := range kv.fields {
+		item[k] = v
+	}
+
+	item[an.PartitionKey] = &dynamodb.AttributeValue{S: aws.String(kv.Partition)}
+	item[an.SortKey] = &dynamodb.AttributeValue{S: aws.String(kv.Key)}
+	item[an.Version] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(kv.Version, base10))}
+
+	if kv.Expires != 0 {
+		item[an.Expires] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(kv.Expires, base10))}
+	}
+
+	if kv.value != nil {
+		item[an.Payload] = kv.value
+	}
+
+	return item
+}
+
+// decodeItem decode a DDB attribute value into a KVPair using the attribute names
+func (an *AttributeNames) decodeItem(item map[string]*dynamodb.AttributeValue) (*KVPair, error) {
+	kv := new(KVPair)
+
+	for name, out := range map[string]interface{}{
+		an.PartitionKey: &kv.Partition,
+		an.SortKey:      &kv.Key,
+		an.Version:      &kv.Version,
+		an.Expires:      &kv.Expires,
+	} {
+		if val, ok := item[name]; ok {
+			err := dynamodbattribute.Unmarshal(val, out)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if val, ok := item[an.Payload]; ok {
+		kv.value = val
+	}
+
+	kv.fields = make(map[string]*dynamodb.AttributeValue)
+
+	for k, v := range item {
+		if !an.isReserved(k) {
+			kv.fields[k] = v
+		}
+	}
+
+	return kv, nil
+}
+
+// isItemExpired the item has a TTL which has passed, this is evaluated to the second to match the
+// conditions and filters evaluated by DynamoDB
+func (an *AttributeNames) isItemExpired(item map[string]*dynamodb.AttributeValue, now time.Time) bool {
+	var ttl int64
+
+	if v, ok := item[an.Expires]; ok {
+		ttl, _ = strconv.ParseInt(aws.StringValue(v.N), base10, int64bits)
+		return ttl <= now.Unix()
+	}
+
+	return false
+}
+
+// notExpiredCondition the record doesn't have a TTL or it is in the future
+func (an *AttributeNames) notExpiredCondition(now time.Time) dexp.ConditionBuilder {
+	// "attribute_not_exists(expires) OR expires > :timeNow"
+	return dexp.Or(
+		dexp.AttributeNotExists(dexp.Name(an.Expires)),
+		dexp.Name(an.Expires).GreaterThan(dexp.Value(now.Unix())),
+	)
+}
+
+// expiredCondition the record has a TTL which has passed
+func (an *AttributeNames) expiredCondition(now time.Time) dexp.ConditionBuilder {
+	// "attribute_exists(expires) AND expires <= :timeNow"
+	return dexp.And(
+		dexp.AttributeExists(dexp.Name(an.Expires)),
+		dexp.Name(an.Expires).LessThanEqual(dexp.Value(now.Unix())),
+	)
+}
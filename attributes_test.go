@@ -0,0 +1,134 @@
+package dynastore
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestAttributeNames_decodeItem(t *testing.T) {
+	custom := AttributeNames{PartitionKey: "pk", SortKey: "sk", Expires: "ttl", Payload: "data"}.withDefaults()
+
+	tests := []struct {
+		name       string
+		attributes AttributeNames
+		item       map[string]*dynamodb.AttributeValue
+		want       *KVPair
+	}{
+		{
+			name:       "should decode default attributes",
+			attributes: DefaultAttributeNames(),
+			item: map[string]*dynamodb.AttributeValue{
+				"id":      {S: aws.String("agent")},
+				"name":    {S: aws.String("key")},
+				"version": {N: aws.String("2")},
+				"expires": {N: aws.String("1600000000")},
+				"payload": {S: aws.String("value")},
+				"created": {S: aws.String("20200103T1100Z")},
+			},
+			want: &KVPair{
+				Partition: "agent",
+				Key:       "key",
+				Version:   2,
+				Expires:   1600000000,
+				value:     &dynamodb.AttributeValue{S: aws.String("value")},
+				fields:    map[string]*dynamodb.AttributeValue{"created": {S: aws.String("20200103T1100Z")}},
+			},
+		},
+		{
+			name:       "should decode custom attributes",
+			attributes: custom,
+			item: map[string]*dynamodb.AttributeValue{
+				"pk":      {S: aws.String("agent")},
+				"sk":      {S: aws.String("key")},
+				"version": {N: aws.String("3")},
+				"ttl":     {N: aws.String("1600000000")},
+				"data":    {S: aws.String("value")},
+				"name":    {S: aws.String("not a key")},
+			},
+			want: &KVPair{
+				Partition: "agent",
+				Key:       "key",
+				Version:   3,
+				Expires:   1600000000,
+				value:     &dynamodb.AttributeValue{S: aws.String("value")},
+				fields:    map[string]*dynamodb.AttributeValue{"name": {S: aws.String("not a key")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.attributes.decodeItem(tt.item)
+			if err != nil {
+				t.Errorf("decodeItem() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeItem() got = %+v, want %+v", got, tt.want)
+			}
+
+			// encoding the record returns the item decoded
+			if item := tt.attributes.encodeItem(got); !reflect.DeepEqual(item, tt.item) {
+				t.Errorf("encodeItem() got = %+v, want %+v", item, tt.item)
+			}
+		})
+	}
+}
+
+func Test_buildUpdateReservedFields(t *testing.T) {
+	custom := AttributeNames{PartitionKey: "pk", SortKey: "sk", Expires: "ttl", Payload: "data"}.withDefaults()
+
+	_, err := buildUpdate(&custom, NewWriteOptions(WriteWithFields(map[string]string{"ttl": "1"})), time.Now())
+	if !errors.Is(err, ErrReservedField) {
+		t.Errorf("buildUpdate() error = %v, wantErr %v", err, ErrReservedField)
+	}
+
+	_, err = buildUpdate(&custom, NewWriteOptions(WriteWithFields(map[string]string{"expires": "1"})), time.Now())
+	if err != nil {
+		t.Errorf("buildUpdate() error = %v", err)
+	}
+}
+
+func TestAttributeNames_isItemExpired(t *testing.T) {
+	attributes := DefaultAttributeNames()
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name string
+		item map[string]*dynamodb.AttributeValue
+		want bool
+	}{
+		{
+			name: "should not expire without a ttl",
+			item: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("agent")}},
+			want: false,
+		},
+		{
+			name: "should not expire with a ttl in the future",
+			item: map[string]*dynamodb.AttributeValue{"expires": {N: aws.String(strconv.FormatInt(now+60, 10))}},
+			want: false,
+		},
+		{
+			name: "should expire with a ttl of now",
+			item: map[string]*dynamodb.AttributeValue{"expires": {N: aws.String(strconv.FormatInt(now, 10))}},
+			want: true,
+		},
+		{
+			name: "should expire with a ttl in the past",
+			item: map[string]*dynamodb.AttributeValue{"expires": {N: aws.String(strconv.FormatInt(now-60, 10))}},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attributes.isItemExpired(tt.item, time.Unix(now, 0)); got != tt.want {
+				t.Errorf("isItemExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
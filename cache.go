@@ -0,0 +1,306 @@
+package dynastore
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// CacheStats the statistics for a cache
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// Cache is an in-process read-through cache for records read using Get, bounded by the number of entries
+// and how long each entry is held.
+//
+// The cache records the highest version observed for each key it holds, so it never serves a record older
+// than one this process has read or written. Writes and deletes made through the session the cache is
+// registered with invalidate entries, consistent reads bypass the cache.
+//
+// Records served from the cache are passed through the interceptors of the table in the same way as a
+// record read from DynamoDB, no request is sent so the request hooks are only invoked for misses.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List
+	entries    map[cacheKey]*list.Element
+	hits       uint64
+	misses     uint64
+}
+
+type cacheKey struct {
+	table     string
+	partition string
+	key       string
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	kv      *KVPair // nil when the record has been invalidated or deleted
+	version int64   // the highest version observed, records below this version are stale
+	expires time.Time
+}
+
+// NewCache create a cache holding up to maxEntries records, each for up to the ttl provided
+func NewCache(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		entries:    make(map[cacheKey]*list.Element),
+	}
+}
+
+// Stats return the hit and miss statistics for the cache
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: c.ll.Len()}
+}
+
+// get return a copy of the cached record if it is present, current and not expired
+func (c *Cache) get(key cacheKey, now time.Time) (*KVPair, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+
+		if entry.kv != nil && now.Before(entry.expires) && !entry.kv.isExpired(now) {
+			c.hits++
+			c.ll.MoveToFront(el)
+
+			kv := *entry.kv
+
+			return &kv, true
+		}
+	}
+
+	c.misses++
+
+	return nil, false
+}
+
+// set store the record unless it is older than the version already observed, returning false if the
+// record is older
+func (c *Cache) set(key cacheKey, kv *KVPair, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+
+	if kv.Version < entry.version {
+		return false
+	}
+
+	entry.kv = kv
+	entry.version = kv.Version
+	entry.expires = now.Add(c.ttl)
+
+	return true
+}
+
+// replace store a record read using a consistent read, this is the latest version of the record so
+// replaces the version observed even if it is lower, such as when a record is deleted then recreated
+func (c *Cache) replace(key cacheKey, kv *KVPair, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+
+	entry.kv = kv
+	entry.version = kv.Version
+	entry.expires = now.Add(c.ttl)
+}
+
+// invalidate remove the cached record, retaining the version observed by a write
+func (c *Cache) invalidate(key cacheKey, version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		if version == 0 {
+			return
+		}
+
+		el = c.ll.PushFront(&cacheEntry{key: key})
+		c.entries[key] = el
+		c.evict()
+	}
+
+	entry := el.Value.(*cacheEntry)
+
+	entry.kv = nil
+
+	if version > entry.version {
+		entry.version = version
+	}
+}
+
+// tombstone remove the cached record when it is deleted, keeping a version above the one deleted so a read
+// which started before the delete can't put the deleted record back. When the version deleted isn't known
+// every version is treated as stale.
+//
+// A record which is recreated starts again at version 1, this is below the tombstone so it is read using a
+// consistent read, which replaces the tombstone, see DynaPartition.GetWithContext.
+func (c *Cache) tombstone(key cacheKey, version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+
+	entry.kv = nil
+
+	observed := int64(math.MaxInt64)
+	if version > 0 {
+		observed = version + 1
+	}
+
+	if observed > entry.version {
+		entry.version = observed
+	}
+}
+
+// entry return the entry for the key, adding one if it is missing
+func (c *Cache) entry(key cacheKey) *cacheEntry {
+	if el, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*cacheEntry)
+	}
+
+	entry := &cacheEntry{key: key}
+
+	c.entries[key] = c.ll.PushFront(entry)
+	c.evict()
+
+	return entry
+}
+
+// evict remove the least recently used entries over the maximum
+func (c *Cache) evict() {
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.entries, el.Value.(*cacheEntry).key)
+	}
+}
+
+// registerCache register a cache with the session so it is invalidated by writes made through the session
+func (ds *DynaSession) registerCache(cache *Cache) {
+	ds.cacheMu.Lock()
+	defer ds.cacheMu.Unlock()
+
+	if ds.caches == nil {
+		ds.caches = make(map[*Cache]struct{})
+	}
+
+	ds.caches[cache] = struct{}{}
+}
+
+// registeredCaches the caches registered with the session
+func (ds *DynaSession) registeredCaches() []*Cache {
+	ds.cacheMu.Lock()
+	defer ds.cacheMu.Unlock()
+
+	caches := make([]*Cache, 0, len(ds.caches))
+	for cache := range ds.caches {
+		caches = append(caches, cache)
+	}
+
+	return caches
+}
+
+// cachedRecordKey the context key holding a record read from the cache
+type cachedRecordKey struct{}
+
+// withCachedRecord return a context holding a record read from the cache, a Get sent with this context is
+// answered using the record once it has passed through the interceptors of the table
+func withCachedRecord(ctx context.Context, kv *KVPair) context.Context {
+	return context.WithValue(ctx, cachedRecordKey{}, kv)
+}
+
+// cachedOutput the output of a get for the record held in the context, ok is false if there is no record or
+// the request reads a different record
+func (dt *DynaTable) cachedOutput(ctx context.Context, input interface{}) (_ interface{}, ok bool) {
+	kv, _ := ctx.Value(cachedRecordKey{}).(*KVPair)
+	if kv == nil {
+		return nil, false
+	}
+
+	getItem, ok := input.(*dynamodb.GetItemInput)
+	if !ok || aws.BoolValue(getItem.ConsistentRead) || aws.StringValue(getItem.TableName) != dt.GetTableName() {
+		return nil, false
+	}
+
+	// an interceptor may have changed the key read
+	if aws.StringValue(getItem.Key[dt.attributes.PartitionKey].S) != kv.Partition ||
+		aws.StringValue(getItem.Key[dt.attributes.SortKey].S) != kv.Key {
+		return nil, false
+	}
+
+	return &dynamodb.GetItemOutput{Item: dt.attributes.encodeItem(kv)}, true
+}
+
+// invalidateCaches invalidate the records written or deleted by the request in the caches registered with
+// the session, this uses the version returned by updates to track the latest version observed
+func (dt *DynaTable) invalidateCaches(input, output interface{}) {
+	caches := dt.session.registeredCaches()
+	if len(caches) == 0 {
+		return
+	}
+
+	keyOf := func(item map[string]*dynamodb.AttributeValue) cacheKey {
+		return cacheKey{
+			table:     dt.GetTableName(),
+			partition: aws.StringValue(item[dt.attributes.PartitionKey].S),
+			key:       aws.StringValue(item[dt.attributes.SortKey].S),
+		}
+	}
+
+	for _, cache := range caches {
+		switch v := input.(type) {
+		case *dynamodb.UpdateItemInput:
+			var version int64
+
+			if out, ok := output.(*dynamodb.UpdateItemOutput); ok && out != nil {
+				if n := out.Attributes[dt.attributes.Version]; n != nil {
+					version, _ = strconv.ParseInt(aws.StringValue(n.N), base10, int64bits)
+				}
+			}
+
+			cache.invalidate(keyOf(v.Key), version)
+		case *dynamodb.DeleteItemInput:
+			var version int64
+
+			// deletes which return the old record report the version deleted
+			if out, ok := output.(*dynamodb.DeleteItemOutput); ok && out != nil {
+				if n := out.Attributes[dt.attributes.Version]; n != nil {
+					version, _ = strconv.ParseInt(aws.StringValue(n.N), base10, int64bits)
+				}
+			}
+
+			cache.tombstone(keyOf(v.Key), version)
+		case *dynamodb.BatchWriteItemInput:
+			for _, req := range v.RequestItems[dt.GetTableName()] {
+				switch {
+				case req.DeleteRequest != nil:
+					cache.tombstone(keyOf(req.DeleteRequest.Key), 0)
+				case req.PutRequest != nil:
+					cache.invalidate(keyOf(req.PutRequest.Item), 0)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	key := func(name string) cacheKey { return cacheKey{table: "testing", partition: "agent", key: name} }
+
+	t.Run("should expire entries after the ttl", func(t *testing.T) {
+		cache := NewCache(10, time.Minute)
+
+		cache.set(key("a"), &KVPair{Key: "a", Version: 1}, now)
+
+		if _, ok := cache.get(key("a"), now.Add(30*time.Second)); !ok {
+			t.Error("get() missed before the ttl")
+		}
+		if _, ok := cache.get(key("a"), now.Add(2*time.Minute)); ok {
+			t.Error("get() hit after the ttl")
+		}
+	})
+
+	t.Run("should not serve records which have expired", func(t *testing.T) {
+		cache := NewCache(10, time.Hour)
+
+		cache.set(key("a"), &KVPair{Key: "a", Version: 1, Expires: now.Add(time.Minute).Unix()}, now)
+
+		if _, ok := cache.get(key("a"), now.Add(2*time.Minute)); ok {
+			t.Error("get() hit after the record expired")
+		}
+	})
+
+	t.Run("should evict the least recently used", func(t *testing.T) {
+		cache := NewCache(2, time.Minute)
+
+		cache.set(key("a"), &KVPair{Key: "a", Version: 1}, now)
+		cache.set(key("b"), &KVPair{Key: "b", Version: 1}, now)
+		cache.get(key("a"), now)
+		cache.set(key("c"), &KVPair{Key: "c", Version: 1}, now)
+
+		if _, ok := cache.get(key("b"), now); ok {
+			t.Error("get() hit evicted entry")
+		}
+		if _, ok := cache.get(key("a"), now); !ok {
+			t.Error("get() missed recently used entry")
+		}
+		if got := cache.Stats().Entries; got != 2 {
+			t.Errorf("Stats() entries = %d, want 2", got)
+		}
+	})
+
+	t.Run("should not store versions older than observed", func(t *testing.T) {
+		cache := NewCache(10, time.Minute)
+
+		cache.invalidate(key("a"), 3)
+		cache.set(key("a"), &KVPair{Key: "a", Version: 2}, now)
+
+		if _, ok := cache.get(key("a"), now); ok {
+			t.Error("get() hit with an older version")
+		}
+
+		cache.set(key("a"), &KVPair{Key: "a", Version: 3}, now)
+
+		if kv, ok := cache.get(key("a"), now); !ok || kv.Version != 3 {
+			t.Errorf("get() = %v, %v want version 3", kv, ok)
+		}
+	})
+
+	t.Run("should keep the version deleted", func(t *testing.T) {
+		cache := NewCache(10, time.Minute)
+
+		cache.set(key("a"), &KVPair{Key: "a", Version: 3}, now)
+		cache.tombstone(key("a"), 3)
+
+		if cache.set(key("a"), &KVPair{Key: "a", Version: 3}, now) {
+			t.Error("set() accepted the deleted version")
+		}
+
+		cache.replace(key("a"), &KVPair{Key: "a", Version: 1}, now)
+
+		if kv, ok := cache.get(key("a"), now); !ok || kv.Version != 1 {
+			t.Errorf("get() = %v, %v want recreated version 1", kv, ok)
+		}
+	})
+
+	t.Run("should reject every version when the version deleted is unknown", func(t *testing.T) {
+		cache := NewCache(10, time.Minute)
+
+		cache.tombstone(key("a"), 0)
+
+		if cache.set(key("a"), &KVPair{Key: "a", Version: 10}, now) {
+			t.Error("set() accepted a record after an unknown delete")
+		}
+	})
+}
+
+// newRecordClient a client which returns the record key with the version provided for each read
+func newRecordClient(t *testing.T, version func(consistent bool) string) *dynamodb.DynamoDB {
+	t.Helper()
+
+	return newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		consistent, _ := body["ConsistentRead"].(bool)
+
+		n := version(consistent)
+		if n == "" {
+			return map[string]interface{}{}
+		}
+
+		return map[string]interface{}{"Item": map[string]interface{}{
+			"id":      map[string]string{"S": "agent"},
+			"name":    map[string]string{"S": "key"},
+			"version": map[string]string{"N": n},
+		}}
+	})
+}
+
+func TestDynaPartition_GetWithCache(t *testing.T) {
+	var reads int
+
+	version := int64(1)
+
+	svc := newRecordClient(t, func(consistent bool) string {
+		reads++
+		return strconv.FormatInt(version, 10)
+	})
+
+	cache := NewCache(10, time.Minute)
+
+	tbl := NewWithClient(svc, defaultHooks).Table("testing")
+	part := tbl.Partition("agent", PartitionWithCache(cache))
+
+	for i := 0; i < 3; i++ {
+		kv, err := part.Get("key")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if kv.Version != 1 {
+			t.Errorf("Get() version = %d, want 1", kv.Version)
+		}
+	}
+
+	if reads != 1 {
+		t.Errorf("reads = %d, want 1", reads)
+	}
+
+	// consistent reads bypass the cache
+	_, err := part.Get("key", func(opts *ReadOptions) { opts.consistent = true })
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if reads != 2 {
+		t.Errorf("reads = %d, want 2", reads)
+	}
+
+	// a write through the session invalidates the entry and records the version written
+	version = 2
+	tbl.invalidateCaches(&dynamodb.UpdateItemInput{Key: tbl.attributes.buildKeys("agent", "key")}, &dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"version": {N: aws.String("2")}},
+	})
+
+	kv, err := part.Get("key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if kv.Version != 2 {
+		t.Errorf("Get() version = %d, want 2", kv.Version)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v, want 2 hits and 2 misses", stats)
+	}
+}
+
+func TestDynaPartition_GetWithCacheInterceptors(t *testing.T) {
+	var reads, intercepted int
+
+	svc := newRecordClient(t, func(consistent bool) string {
+		reads++
+		return "1"
+	})
+
+	errDenied := errors.New("denied")
+
+	var deny bool
+
+	denyInterceptor := func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+		intercepted++
+
+		if deny {
+			return nil, errDenied
+		}
+
+		return next(ctx, op)
+	}
+
+	tbl := NewWithClient(svc, defaultHooks).Table("testing", TableWithInterceptors(denyInterceptor))
+	part := tbl.Partition("agent", PartitionWithCache(NewCache(10, time.Minute)))
+
+	for i := 0; i < 2; i++ {
+		if _, err := part.Get("key"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+
+	// the cache hit passes through the interceptor without sending a request
+	if intercepted != 2 || reads != 1 {
+		t.Errorf("intercepted = %d, reads = %d, want 2 and 1", intercepted, reads)
+	}
+
+	deny = true
+
+	if _, err := part.Get("key"); !errors.Is(err, errDenied) {
+		t.Errorf("Get() error = %v, want %v", err, errDenied)
+	}
+}
+
+func TestDynaPartition_GetWithCacheDeleted(t *testing.T) {
+	var consistentReads int
+
+	// eventually consistent reads still return the deleted record
+	svc := newRecordClient(t, func(consistent bool) string {
+		if consistent {
+			consistentReads++
+			return ""
+		}
+
+		return "3"
+	})
+
+	cache := NewCache(10, time.Minute)
+
+	tbl := NewWithClient(svc, defaultHooks).Table("testing")
+	part := tbl.Partition("agent", PartitionWithCache(cache))
+
+	// a delete through the session returns the record deleted
+	tbl.invalidateCaches(&dynamodb.DeleteItemInput{Key: tbl.attributes.buildKeys("agent", "key")}, &dynamodb.DeleteItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"version": {N: aws.String("3")}},
+	})
+
+	_, err := part.Get("key")
+	if err != ErrKeyNotFound {
+		t.Fatalf("Get() error = %v, want %v", err, ErrKeyNotFound)
+	}
+	if consistentReads != 1 {
+		t.Errorf("consistent reads = %d, want 1", consistentReads)
+	}
+}
+
+func TestDynaPartition_GetWithCacheConditionFailed(t *testing.T) {
+	var reads int
+
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		if target == "DeleteItem" {
+			return testErrorCode(dynamodb.ErrCodeConditionalCheckFailedException)
+		}
+
+		reads++
+
+		return map[string]interface{}{"Item": map[string]interface{}{
+			"id":      map[string]string{"S": "agent"},
+			"name":    map[string]string{"S": "key"},
+			"version": map[string]string{"N": "1"},
+		}}
+	})
+
+	cache := NewCache(10, time.Minute)
+
+	part := NewWithClient(svc, defaultHooks).Table("testing").Partition("agent", PartitionWithCache(cache))
+
+	kv, err := part.Get("key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// the delete didn't happen so the cached record is still current
+	if _, err := part.AtomicDelete("key", &KVPair{Partition: "agent", Key: "key", Version: 2}); err == nil {
+		t.Fatal("AtomicDelete() error = nil, want a failed condition")
+	}
+
+	kv, err = part.Get("key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if kv.Version != 1 || reads != 1 {
+		t.Errorf("Get() version = %d, reads = %d, want 1 and 1", kv.Version, reads)
+	}
+}
+
+func TestDynaPartition_GetWithCacheStaleRead(t *testing.T) {
+	var reads, consistentReads int
+
+	// eventually consistent reads return an older version than consistent reads
+	svc := newRecordClient(t, func(consistent bool) string {
+		reads++
+		if consistent {
+			consistentReads++
+			return "2"
+		}
+
+		return "1"
+	})
+
+	cache := NewCache(10, time.Minute)
+
+	tbl := NewWithClient(svc, defaultHooks).Table("testing")
+	part := tbl.Partition("agent", PartitionWithCache(cache))
+
+	// a write through the session has observed version 2
+	cache.invalidate(cacheKey{table: "testing", partition: "agent", key: "key"}, 2)
+
+	kv, err := part.Get("key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if kv.Version != 2 {
+		t.Errorf("Get() version = %d, want 2", kv.Version)
+	}
+	if reads != 2 || consistentReads != 1 {
+		t.Errorf("reads = %d, consistent reads = %d, want 2 and 1", reads, consistentReads)
+	}
+
+	// the consistent read is cached
+	kv, err = part.Get("key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if kv.Version != 2 || reads != 2 {
+		t.Errorf("Get() version = %d, reads = %d, want 2 and 2", kv.Version, reads)
+	}
+}
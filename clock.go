@@ -0,0 +1,56 @@
+package dynastore
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time used to compute TTLs and evaluate expiry, this enables tests
+// to control time and simulate clock skew between hosts.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock a clock which only moves when it is set or advanced, this is used to test expiry
+// without waiting in real time.
+//
+// Use KVPair.TTLRemainingAt with the time of this clock, TTLRemaining always uses the system clock.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock create a fake clock set to the time provided
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now return the current time of the fake clock
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.now
+}
+
+// Set the current time of the fake clock
+func (fc *FakeClock) Set(now time.Time) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = now
+}
+
+// Advance move the fake clock forward by the duration provided
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = fc.now.Add(d)
+}
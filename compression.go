@@ -3,12 +3,19 @@ package dynastore
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"sort"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/mr-tron/base58"
 )
 
+// compressAndEncodeKey encode a key using the legacy format of gzip compressed JSON encoded as base58,
+// this has been replaced by encodeKey
 func compressAndEncodeKey(key map[string]*dynamodb.AttributeValue) (string, error) {
 	buf := new(bytes.Buffer)
 
@@ -26,6 +33,7 @@ func compressAndEncodeKey(key map[string]*dynamodb.AttributeValue) (string, erro
 	return base58.Encode(buf.Bytes()), nil
 }
 
+// decompressAndDecodeKey decode a key encoded by compressAndEncodeKey
 func decompressAndDecodeKey(key string) (map[string]*dynamodb.AttributeValue, error) {
 	data, err := base58.Decode(key)
 	if err != nil {
@@ -48,3 +56,126 @@ func decompressAndDecodeKey(key string) (map[string]*dynamodb.AttributeValue, er
 
 	return m, err
 }
+
+// keyEncodingVersion the version byte which leads each compact key token
+const keyEncodingVersion byte = 1
+
+// attribute types stored in a compact key token, DynamoDB keys can only be strings, numbers or binary
+const (
+	keyAttributeString byte = 'S'
+	keyAttributeNumber byte = 'N'
+	keyAttributeBinary byte = 'B'
+)
+
+// encodeKey encode a key using the compact binary format, this is a version byte followed by each attribute
+// as a length-prefixed name, a type and a length-prefixed value, encoded using base64url
+func encodeKey(key map[string]*dynamodb.AttributeValue) (string, error) {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+
+	// sorted so the same key is always encoded to the same token
+	sort.Strings(names)
+
+	buf := make([]byte, 1, 64)
+	buf[0] = keyEncodingVersion
+
+	for _, name := range names {
+		val := key[name]
+
+		var (
+			typ   byte
+			value []byte
+		)
+
+		switch {
+		case val == nil:
+			return "", fmt.Errorf("attribute %s has no value: %w", name, ErrInvalidKeyEncoding)
+		case val.S != nil:
+			typ, value = keyAttributeString, []byte(*val.S)
+		case val.N != nil:
+			typ, value = keyAttributeNumber, []byte(*val.N)
+		case val.B != nil:
+			typ, value = keyAttributeBinary, val.B
+		default:
+			return "", fmt.Errorf("attribute %s isn't a string, number or binary: %w", name, ErrInvalidKeyEncoding)
+		}
+
+		buf = appendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+		buf = append(buf, typ)
+		buf = appendUvarint(buf, uint64(len(value)))
+		buf = append(buf, value...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(tmp[:], v)
+
+	return append(buf, tmp[:n]...)
+}
+
+// decodeKey decode a key encoded by encodeKey, falling back to the legacy format of compressAndEncodeKey
+func decodeKey(token string) (map[string]*dynamodb.AttributeValue, error) {
+	if data, err := base64.RawURLEncoding.DecodeString(token); err == nil && len(data) != 0 && data[0] == keyEncodingVersion {
+		if key, err := decodeCompactKey(data[1:]); err == nil {
+			return key, nil
+		}
+	}
+
+	return decompressAndDecodeKey(token)
+}
+
+func decodeCompactKey(data []byte) (map[string]*dynamodb.AttributeValue, error) {
+	key := make(map[string]*dynamodb.AttributeValue)
+
+	// read a length-prefixed field from the front of the data
+	field := func() ([]byte, error) {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			return nil, ErrInvalidKeyEncoding
+		}
+
+		value := data[n : n+int(size)]
+		data = data[n+int(size):]
+
+		return value, nil
+	}
+
+	for len(data) != 0 {
+		name, err := field()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) == 0 {
+			return nil, ErrInvalidKeyEncoding
+		}
+
+		typ := data[0]
+		data = data[1:]
+
+		value, err := field()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case keyAttributeString:
+			key[string(name)] = &dynamodb.AttributeValue{S: aws.String(string(value))}
+		case keyAttributeNumber:
+			key[string(name)] = &dynamodb.AttributeValue{N: aws.String(string(value))}
+		case keyAttributeBinary:
+			key[string(name)] = &dynamodb.AttributeValue{B: append([]byte{}, value...)}
+		default:
+			return nil, ErrInvalidKeyEncoding
+		}
+	}
+
+	return key, nil
+}
@@ -71,3 +71,158 @@ func Test_decompressAndDecodeKey(t *testing.T) {
 		})
 	}
 }
+
+func Test_encodeKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     map[string]*dynamodb.AttributeValue
+		wantErr bool
+	}{
+		{
+			name: "should encode string key",
+			key: map[string]*dynamodb.AttributeValue{
+				"id":   {S: aws.String("agent")},
+				"name": {S: aws.String("testList/subfolder/key1")},
+			},
+		},
+		{
+			name: "should encode number and binary key",
+			key: map[string]*dynamodb.AttributeValue{
+				"id":      {S: aws.String("agent")},
+				"created": {N: aws.String("1700000000")},
+				"hash":    {B: []byte{0, 1, 2, 255}},
+			},
+		},
+		{
+			name: "should encode empty key",
+			key:  map[string]*dynamodb.AttributeValue{},
+		},
+		{
+			name: "should fail to encode non key attribute",
+			key: map[string]*dynamodb.AttributeValue{
+				"id": {BOOL: aws.Bool(true)},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := encodeKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encodeKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := decodeKey(token)
+			if err != nil {
+				t.Fatalf("decodeKey() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.key) {
+				t.Errorf("decodeKey() got = %v, want %v", got, tt.key)
+			}
+		})
+	}
+}
+
+func Test_decodeKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    map[string]*dynamodb.AttributeValue
+		wantErr bool
+	}{
+		{
+			name:  "should decode compact key",
+			token: "AQJpZFMHd2VsY29tZQ",
+			want: map[string]*dynamodb.AttributeValue{
+				"id": {S: aws.String("welcome")},
+			},
+		},
+		{
+			name:  "should decode legacy key",
+			token: "43hq9ZEtH5MmQ4HqcunoWHkJBoQUnu22Dsa1L9xdfG7ReiUJqPULC8AqoQxYg3jswJH4gGSncjrachbipBHxxmvTAryjUCj2sTomdzs8",
+			want: map[string]*dynamodb.AttributeValue{
+				"id": {S: aws.String("welcome")},
+			},
+		},
+		{
+			name:    "should fail to decode truncated key",
+			token:   "AQJpZFMHd2Vs",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeKey(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeKey() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var benchmarkKey = map[string]*dynamodb.AttributeValue{
+	"id":   {S: aws.String("agent")},
+	"name": {S: aws.String("testList/subfolder/key1")},
+}
+
+func Benchmark_encodeKey(b *testing.B) {
+	benchmarkEncode(b, encodeKey)
+}
+
+func Benchmark_compressAndEncodeKey(b *testing.B) {
+	benchmarkEncode(b, compressAndEncodeKey)
+}
+
+func Benchmark_decodeKey(b *testing.B) {
+	token, err := encodeKey(benchmarkKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkDecode(b, token, decodeKey)
+}
+
+func Benchmark_decompressAndDecodeKey(b *testing.B) {
+	token, err := compressAndEncodeKey(benchmarkKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkDecode(b, token, decompressAndDecodeKey)
+}
+
+// benchmarkEncode report the size of the token along with the cost of encoding it
+func benchmarkEncode(b *testing.B, encode func(map[string]*dynamodb.AttributeValue) (string, error)) {
+	var token string
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var err error
+
+		token, err = encode(benchmarkKey)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(len(token)), "token-bytes")
+}
+
+func benchmarkDecode(b *testing.B, token string, decode func(string) (map[string]*dynamodb.AttributeValue, error)) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := decode(token); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(len(token)), "token-bytes")
+}
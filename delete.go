@@ -0,0 +1,154 @@
+package dynastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+const (
+	// batchWriteMaxItems the maximum number of items DynamoDB accepts in a single batch write
+	batchWriteMaxItems = 25
+
+	defaultBatchMaxRetries = 8
+)
+
+// DeletePrefixWithContext delete all the records in a partition with a sort key starting with the given prefix
+//
+// The keys are read a page at a time using a key only query, then deleted in batches. After each page is
+// deleted progress is reported, the LastKey in the progress can be used with DeleteWithStartKey to resume
+// if the operation is interrupted.
+func (dt *DynaTable) DeletePrefixWithContext(ctx context.Context, partitionKey, prefix string, options ...DeletePrefixOption) (_ *DeleteProgress, err error) {
+	deleteOptions := NewDeletePrefixOptions(options...)
+
+	ctx, done := dt.session.startOperation(ctx, "DeletePrefix", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
+
+	key := dexp.Key(dt.attributes.PartitionKey).Equal(dexp.Value(partitionKey))
+
+	if prefix != "" {
+		key = key.And(dexp.Key(dt.attributes.SortKey).BeginsWith(prefix))
+	}
+
+	proj := dexp.NamesList(dexp.Name(dt.attributes.PartitionKey), dexp.Name(dt.attributes.SortKey))
+
+	expr, err := dexp.NewBuilder().WithKeyCondition(key).WithProjection(proj).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build exp: %w", err)
+	}
+
+	query := &dynamodb.QueryInput{
+		TableName:                 aws.String(dt.GetTableName()),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     deleteOptions.pageSize,
+		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
+	}
+
+	// avoid either a nil or empty value
+	if startKey := aws.StringValue(deleteOptions.startKey); startKey != "" {
+		query.ExclusiveStartKey, err = dt.decodePageToken(deletePrefixScope(partitionKey, prefix), startKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key: %w", err)
+		}
+	}
+
+	progress := &DeleteProgress{DryRun: deleteOptions.dryRun}
+
+	for {
+		res, err := sendTable(ctx, dt, query, dt.session.QueryWithContext)
+		if err != nil {
+			return progress, fmt.Errorf("failed to run query: %w", err)
+		}
+
+		if !deleteOptions.dryRun {
+			err = dt.batchDelete(ctx, res.Items, deleteOptions.maxRetries)
+			if err != nil {
+				return progress, err
+			}
+		}
+
+		progress.Deleted += len(res.Items)
+		progress.LastKey = ""
+
+		if len(res.LastEvaluatedKey) != 0 {
+			progress.LastKey, err = dt.encodePageToken(deletePrefixScope(partitionKey, prefix), res.LastEvaluatedKey)
+			if err != nil {
+				return progress, fmt.Errorf("failed to encode key: %w", err)
+			}
+		}
+
+		if deleteOptions.progress != nil {
+			deleteOptions.progress(progress)
+		}
+
+		if len(res.LastEvaluatedKey) == 0 {
+			return progress, nil
+		}
+
+		query.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+}
+
+// batchDelete delete the keys provided in batches, retrying any unprocessed items with backoff
+func (dt *DynaTable) batchDelete(ctx context.Context, keys []map[string]*dynamodb.AttributeValue, maxRetries int) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > batchWriteMaxItems {
+			n = batchWriteMaxItems
+		}
+
+		requests := make([]*dynamodb.WriteRequest, n)
+		for i, key := range keys[:n] {
+			requests[i] = &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}}
+		}
+
+		err := dt.batchWrite(ctx, requests, maxRetries)
+		if err != nil {
+			return err
+		}
+
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+// batchWrite send the write requests, unprocessed items are retried with a delay from the retry policy
+// for the operation until maxRetries is reached
+func (dt *DynaTable) batchWrite(ctx context.Context, requests []*dynamodb.WriteRequest, maxRetries int) error {
+	policy := dt.session.retryPolicy(OperationName(ctx))
+
+	for attempt := 0; ; attempt++ {
+		batchWrite := &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{
+				dt.GetTableName(): requests,
+			},
+			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		}
+
+		res, err := sendTable(ctx, dt, batchWrite, dt.session.BatchWriteItemWithContext)
+		if err != nil {
+			return fmt.Errorf("failed to batch write items: %w", err)
+		}
+
+		requests = res.UnprocessedItems[dt.GetTableName()]
+		if len(requests) == 0 {
+			return nil
+		}
+
+		if attempt >= maxRetries {
+			return fmt.Errorf("%d items left after %d retries: %w", len(requests), attempt, ErrUnprocessedItems)
+		}
+
+		err = sleepWithContext(ctx, policy.delay(attempt+1))
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -3,6 +3,7 @@ package dynastore
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
@@ -13,6 +14,15 @@ const (
 
 	// DefaultSortKeyAttribute this is the default sort key attribute name used throughout dynastore
 	DefaultSortKeyAttribute = "name"
+
+	// DefaultVersionAttribute this is the default version attribute name used throughout dynastore
+	DefaultVersionAttribute = "version"
+
+	// DefaultExpiresAttribute this is the default time to live (TTL) attribute name used throughout dynastore
+	DefaultExpiresAttribute = "expires"
+
+	// DefaultPayloadAttribute this is the default payload attribute name used throughout dynastore
+	DefaultPayloadAttribute = "payload"
 )
 
 var (
@@ -25,12 +35,67 @@ var (
 	// ErrKeyModified record has been modified, this probably means someone beat you to the change/lock
 	ErrKeyModified = errors.New("key has been modified")
 
+	// ErrKeyExpired record exists in the table but its time to live (TTL) has passed
+	ErrKeyExpired = errors.New("key has expired")
+
 	// ErrReservedField put contained an field in the write options which was reserved
 	ErrReservedField = errors.New("fields contained reserved attribute name")
 
+	// ErrFieldConflict put both assigned and removed the same field
+	ErrFieldConflict = errors.New("field both assigned and removed")
+
 	// ErrIndexNotSupported dynamodb get operations don't support specifying an index
 	ErrIndexNotSupported = errors.New("indexes not supported for this operation")
 
+	// ErrUnknownIndex the named index hasn't been registered with the table
+	ErrUnknownIndex = errors.New("index not registered with table")
+
+	// ErrIndexKeyMissing the fields written are missing a key attribute of an index the record belongs to
+	ErrIndexKeyMissing = errors.New("fields missing index key attribute")
+
+	// ErrInvalidScanSegments the segments provided to a scan are out of range
+	ErrInvalidScanSegments = errors.New("invalid scan segments")
+
+	// ErrUnprocessedItems batch write still had unprocessed items after all retries were exhausted
+	ErrUnprocessedItems = errors.New("batch write has unprocessed items")
+
+	// ErrInvalidSchema the table schema is missing required settings or is inconsistent
+	ErrInvalidSchema = errors.New("invalid table schema")
+
+	// ErrSchemaConflict the existing table conflicts with the table schema, returned wrapped in a SchemaConflictError
+	ErrSchemaConflict = errors.New("table conflicts with schema")
+
+	// ErrThrottled the request was throttled by DynamoDB, returned wrapped in an Error
+	ErrThrottled = errors.New("request throttled")
+
+	// ErrTableNotFound the table or index doesn't exist, returned wrapped in an Error
+	ErrTableNotFound = errors.New("table not found")
+
+	// ErrValidation the request was rejected as invalid by DynamoDB, returned wrapped in an Error
+	ErrValidation = errors.New("request failed validation")
+
+	// ErrItemTooLarge the item exceeds the maximum size supported by DynamoDB, returned wrapped in an Error
+	ErrItemTooLarge = errors.New("item too large")
+
+	// ErrItemCollectionTooLarge the items in a partition exceed the size limit of a table with a local index,
+	// returned wrapped in an Error
+	ErrItemCollectionTooLarge = errors.New("item collection too large")
+
+	// ErrTransactionConflict the request conflicts with an ongoing transaction, returned wrapped in an Error
+	ErrTransactionConflict = errors.New("transaction conflict")
+
+	// ErrRequestTimeout the request timed out before DynamoDB responded, returned wrapped in an Error
+	ErrRequestTimeout = errors.New("request timed out")
+
+	// ErrInterceptorType an interceptor replaced the input or returned a result with a type other than the AWS SDK type
+	ErrInterceptorType = errors.New("interceptor changed the type of the request or result")
+
+	// ErrInvalidPageToken the page token provided isn't signed by the session, has expired or came from another read
+	ErrInvalidPageToken = errors.New("invalid page token")
+
+	// ErrInvalidKeyEncoding the key can't be encoded or decoded using the compact key format
+	ErrInvalidKeyEncoding = errors.New("invalid key encoding")
+
 	_ Table     = &DynaTable{}
 	_ Partition = &DynaPartition{}
 )
@@ -41,7 +106,10 @@ type Session interface {
 	dynamodbiface.DynamoDBAPI
 
 	// Table returns a table
-	Table(tableName string) Table
+	Table(tableName string, options ...TableOption) Table
+
+	// EnsureTable create or update the table to match the schema
+	EnsureTable(ctx context.Context, schema *TableSchema) error
 }
 
 // Table represents a table in DynamoDB, this is where you store all your partitioned data for a given
@@ -49,21 +117,33 @@ type Session interface {
 type Table interface {
 	GetTableName() string
 
-	Partition(partitionName string) Partition
+	Partition(partitionName string, options ...PartitionOption) Partition
 
 	PutWithContext(ctx context.Context, partitionKey, sortKey string, options ...WriteOption) error
 
+	PutKVWithContext(ctx context.Context, partitionKey, sortKey string, options ...WriteOption) (*KVPair, error)
+
 	GetWithContext(ctx context.Context, partitionKey, sortKey string, options ...ReadOption) (*KVPair, error)
 
 	ListPageWithContext(ctx context.Context, partitionKey, prefix string, options ...ReadOption) (*KVPairPage, error)
 
 	DeleteWithContext(ctx context.Context, partitionKey, sortKey string) error
 
+	DeleteKVWithContext(ctx context.Context, partitionKey, sortKey string) (bool, *KVPair, error)
+
 	ExistsWithContext(ctx context.Context, partitionKey, sortKey string, options ...ReadOption) (bool, error)
 
 	AtomicPutWithContext(ctx context.Context, partitionKey, sortKey string, options ...WriteOption) (bool, *KVPair, error)
 
 	AtomicDeleteWithContext(ctx context.Context, partitionKey, sortKey string, previous *KVPair) (bool, error)
+
+	Scan(ctx context.Context, fn ScanPageFunc, options ...ScanOption) error
+
+	DeletePrefixWithContext(ctx context.Context, partitionKey, prefix string, options ...DeletePrefixOption) (*DeleteProgress, error)
+
+	UpdateWithContext(ctx context.Context, partitionKey, sortKey string, fn UpdateFunc, options ...UpdateOption) (*KVPair, error)
+
+	TouchWithContext(ctx context.Context, partitionKey, sortKey string, ttl time.Duration, previous *KVPair) (*KVPair, error)
 }
 
 // Partition a partition represents a grouping of data within a DynamoDB table.
@@ -74,6 +154,10 @@ type Partition interface {
 
 	PutWithContext(ctx context.Context, sortKey string, options ...WriteOption) error
 
+	PutKV(sortKey string, options ...WriteOption) (*KVPair, error)
+
+	PutKVWithContext(ctx context.Context, sortKey string, options ...WriteOption) (*KVPair, error)
+
 	Get(key string, options ...ReadOption) (*KVPair, error)
 
 	GetWithContext(ctx context.Context, sortKey string, options ...ReadOption) (*KVPair, error)
@@ -90,6 +174,10 @@ type Partition interface {
 
 	DeleteWithContext(ctx context.Context, sortKey string) error
 
+	DeleteKV(sortKey string) (bool, *KVPair, error)
+
+	DeleteKVWithContext(ctx context.Context, sortKey string) (bool, *KVPair, error)
+
 	Exists(sortKey string, options ...ReadOption) (bool, error)
 
 	ExistsWithContext(ctx context.Context, sortKey string, options ...ReadOption) (bool, error)
@@ -101,12 +189,31 @@ type Partition interface {
 	AtomicDelete(sortKey string, previous *KVPair) (bool, error)
 
 	AtomicDeleteWithContext(ctx context.Context, sortKey string, previous *KVPair) (bool, error)
+
+	DeletePrefix(prefix string, options ...DeletePrefixOption) (*DeleteProgress, error)
+
+	DeletePrefixWithContext(ctx context.Context, prefix string, options ...DeletePrefixOption) (*DeleteProgress, error)
+
+	Update(sortKey string, fn UpdateFunc, options ...UpdateOption) (*KVPair, error)
+
+	UpdateWithContext(ctx context.Context, sortKey string, fn UpdateFunc, options ...UpdateOption) (*KVPair, error)
+
+	Touch(sortKey string, ttl time.Duration, previous *KVPair) (*KVPair, error)
+
+	TouchWithContext(ctx context.Context, sortKey string, ttl time.Duration, previous *KVPair) (*KVPair, error)
 }
 
 // StoreHooks is a container for callbacks that can instrument the datastore
 type StoreHooks struct {
 	// RequestBuilt will be invoked prior to dispatching the request to the AWS SDK
 	RequestBuilt func(ctx context.Context, params interface{}) context.Context
+	// RequestCompleted will be invoked once the AWS SDK has completed the request, successfully or not
+	RequestCompleted func(ctx context.Context, info *RequestInfo)
+	// OperationStarted will be invoked when a dynastore operation starts, the context returned is used for
+	// each of the requests sent by the operation
+	OperationStarted func(ctx context.Context, info *OperationInfo) context.Context
+	// OperationCompleted will be invoked once the dynastore operation has completed, successfully or not
+	OperationCompleted func(ctx context.Context, info *OperationInfo)
 }
 
 var defaultHooks = &StoreHooks{
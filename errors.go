@@ -0,0 +1,166 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Error is returned when a request to DynamoDB fails, it carries the operation, table and key the request
+// was sent for and wraps the AWS error.
+//
+// The kind of failure can be checked using errors.Is with ErrThrottled, ErrTableNotFound, ErrValidation,
+// ErrItemTooLarge, ErrItemCollectionTooLarge, ErrTransactionConflict or ErrRequestTimeout.
+type Error struct {
+	// Op the name of the dynastore operation, see OperationName
+	Op string
+	// Table the name of the table
+	Table string
+	// PartitionKey the partition key, this is empty for scans and table operations
+	PartitionKey string
+	// SortKey the sort key, this is empty for operations which read more than one record
+	SortKey string
+	// Kind the sentinel error matching the failure, this is nil for failures which aren't classified
+	Kind error
+	// Err the error returned by the AWS SDK
+	Err error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s on table %s", e.Op, e.Table)
+
+	if e.PartitionKey != "" {
+		fmt.Fprintf(&sb, " key %s/%s", e.PartitionKey, e.SortKey)
+	}
+
+	if e.Kind != nil {
+		fmt.Fprintf(&sb, ": %s", e.Kind)
+	}
+
+	fmt.Fprintf(&sb, ": %s", e.Err)
+
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the sentinel error for the kind of failure
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+// wrapError wrap an error returned by the AWS SDK in an Error, other errors are returned as is
+func wrapError(ctx context.Context, tableName, partitionKey, sortKey string, err error) error {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return err
+	}
+
+	return &Error{
+		Op:           OperationName(ctx),
+		Table:        tableName,
+		PartitionKey: partitionKey,
+		SortKey:      sortKey,
+		Kind:         errorKind(ctx, awsErr),
+		Err:          err,
+	}
+}
+
+// errorKind the sentinel error matching the AWS error code
+func errorKind(ctx context.Context, awsErr awserr.Error) error {
+	switch awsErr.Code() {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException, dynamodb.ErrCodeRequestLimitExceeded, "ThrottlingException":
+		return ErrThrottled
+	case dynamodb.ErrCodeResourceNotFoundException:
+		return ErrTableNotFound
+	case dynamodb.ErrCodeItemCollectionSizeLimitExceededException:
+		return ErrItemCollectionTooLarge
+	case "ValidationException":
+		// DynamoDB reports items which are too large as a validation failure
+		if strings.Contains(awsErr.Message(), "Item size") {
+			return ErrItemTooLarge
+		}
+		return ErrValidation
+	case dynamodb.ErrCodeTransactionConflictException:
+		return ErrTransactionConflict
+	case "RequestTimeout", "RequestTimeoutException":
+		return ErrRequestTimeout
+	case request.CanceledErrorCode:
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrRequestTimeout
+		}
+	}
+
+	return nil
+}
+
+// ConflictError is returned when a conditional write fails, it wraps either ErrKeyExists or ErrKeyModified
+// so can be checked using errors.Is, and carries the current record so callers can resolve the conflict
+// without reading it again.
+type ConflictError struct {
+	Err error
+
+	// Current the record as it was when the condition failed, this is nil if the record doesn't exist or has expired
+	Current *KVPair
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// conditionFailed if the error is a conditional check failure return the current item returned with it
+func conditionFailed(err error) (map[string]*dynamodb.AttributeValue, bool) {
+	var ccfErr *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &ccfErr) {
+		return ccfErr.Item, true
+	}
+
+	// fall back to the error code for errors which aren't the modelled exception type
+	if isAWSErrorCode(err, dynamodb.ErrCodeConditionalCheckFailedException) {
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// existingConditionError classify the failure of a condition built with existingWithConditions using
+// the current item returned with it
+func (dt *DynaTable) existingConditionError(current map[string]*dynamodb.AttributeValue) error {
+	switch {
+	case len(current) == 0:
+		return ErrKeyNotFound
+	case dt.attributes.isItemExpired(current, dt.now()):
+		return ErrKeyExpired
+	default:
+		return dt.newConflictError(ErrKeyModified, current)
+	}
+}
+
+// newConflictError wrap the sentinel error with the current item returned by the failed condition check
+func (dt *DynaTable) newConflictError(sentinel error, item map[string]*dynamodb.AttributeValue) error {
+	conflict := &ConflictError{Err: sentinel}
+
+	if len(item) == 0 || dt.attributes.isItemExpired(item, dt.now()) {
+		return conflict
+	}
+
+	current, err := dt.attributes.decodeItem(item)
+	if err == nil {
+		conflict.Current = current
+	}
+
+	return conflict
+}
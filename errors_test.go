@@ -0,0 +1,167 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestDynaTable_newConflictError(t *testing.T) {
+	tbl := NewWithClient(nil, defaultHooks).Table("testing")
+
+	tests := []struct {
+		name        string
+		sentinel    error
+		item        map[string]*dynamodb.AttributeValue
+		wantVersion int64
+	}{
+		{
+			name:     "should wrap sentinel with current record",
+			sentinel: ErrKeyModified,
+			item: map[string]*dynamodb.AttributeValue{
+				"id":      {S: aws.String("agent")},
+				"name":    {S: aws.String("key")},
+				"version": {N: aws.String("5")},
+			},
+			wantVersion: 5,
+		},
+		{
+			name:     "should omit expired record",
+			sentinel: ErrKeyExists,
+			item: map[string]*dynamodb.AttributeValue{
+				"id":      {S: aws.String("agent")},
+				"name":    {S: aws.String("key")},
+				"version": {N: aws.String("5")},
+				"expires": {N: aws.String("1")},
+			},
+		},
+		{
+			name:     "should handle missing record",
+			sentinel: ErrKeyModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tbl.newConflictError(tt.sentinel, tt.item)
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("newConflictError() error = %v, want %v", err, tt.sentinel)
+			}
+
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("newConflictError() error = %T, want *ConflictError", err)
+			}
+
+			if tt.wantVersion == 0 {
+				if conflict.Current != nil {
+					t.Errorf("newConflictError() current = %+v, want nil", conflict.Current)
+				}
+				return
+			}
+
+			if conflict.Current == nil || conflict.Current.Version != tt.wantVersion {
+				t.Errorf("newConflictError() current = %+v, want version %d", conflict.Current, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func Test_wrapError(t *testing.T) {
+	ctx := setOperationName(context.Background(), "Get")
+
+	deadlineCtx, cancel := context.WithTimeout(ctx, 0)
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		want    error
+		wantMsg string
+	}{
+		{
+			name:    "should classify throttling",
+			ctx:     ctx,
+			err:     awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil),
+			want:    ErrThrottled,
+			wantMsg: "Get on table testing key agent/key: request throttled: ProvisionedThroughputExceededException: throttled",
+		},
+		{
+			name: "should classify missing table",
+			ctx:  ctx,
+			err:  awserr.New(dynamodb.ErrCodeResourceNotFoundException, "missing", nil),
+			want: ErrTableNotFound,
+		},
+		{
+			name: "should classify item too large",
+			ctx:  ctx,
+			err:  awserr.New("ValidationException", "Item size has exceeded the maximum allowed size", nil),
+			want: ErrItemTooLarge,
+		},
+		{
+			name: "should classify item collection too large",
+			ctx:  ctx,
+			err:  awserr.New(dynamodb.ErrCodeItemCollectionSizeLimitExceededException, "Item collection size limit exceeded", nil),
+			want: ErrItemCollectionTooLarge,
+		},
+		{
+			name: "should classify validation",
+			ctx:  ctx,
+			err:  awserr.New("ValidationException", "One or more parameter values were invalid", nil),
+			want: ErrValidation,
+		},
+		{
+			name: "should classify transaction conflict",
+			ctx:  ctx,
+			err:  awserr.New(dynamodb.ErrCodeTransactionConflictException, "conflict", nil),
+			want: ErrTransactionConflict,
+		},
+		{
+			name: "should classify deadline as a timeout",
+			ctx:  deadlineCtx,
+			err:  awserr.New(request.CanceledErrorCode, "canceled", context.DeadlineExceeded),
+			want: ErrRequestTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapError(tt.ctx, "testing", "agent", "key", tt.err)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("wrapError() error = %v, want %v", err, tt.want)
+			}
+
+			var dErr *Error
+			if !errors.As(err, &dErr) {
+				t.Fatalf("wrapError() error = %T, want *Error", err)
+			}
+			if dErr.Kind != tt.want {
+				t.Errorf("wrapError() kind = %v, want %v", dErr.Kind, tt.want)
+			}
+			if dErr.Op != "Get" || dErr.Table != "testing" || dErr.PartitionKey != "agent" || dErr.SortKey != "key" {
+				t.Errorf("wrapError() error = %+v", dErr)
+			}
+
+			// the AWS error is still available to callers
+			var awsErr awserr.Error
+			if !errors.As(err, &awsErr) {
+				t.Errorf("wrapError() error = %v, want awserr.Error", err)
+			}
+
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("wrapError() message = %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func Test_wrapErrorOther(t *testing.T) {
+	err := wrapError(context.Background(), "testing", "", "", context.Canceled)
+	if err != context.Canceled {
+		t.Errorf("wrapError() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,414 @@
+package dynastore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// RequestInfo describes a request which has completed, this is passed to the RequestCompleted hook
+type RequestInfo struct {
+	// Operation the name of the dynastore operation which sent the request, see OperationName
+	Operation string
+	// TableName the name of the table the request was sent to
+	TableName string
+	// PartitionKey the partition the request was sent to, this is empty for scans and table operations
+	PartitionKey string
+	// IndexName the name of the index read by a query or scan, this is empty when reading the table
+	IndexName string
+	// Input the AWS SDK input for the request
+	Input interface{}
+	// Output the AWS SDK output for the request
+	Output interface{}
+	// Duration the time taken to complete the request including any retries
+	Duration time.Duration
+	// Attempt the number of the attempt made by the dynastore retry policy, starting at 1
+	Attempt int
+	// Retries the number of times the request was retried by the AWS SDK
+	Retries int
+	// Err the error returned by the request
+	Err error
+	// ConditionFailed the request failed because the condition expression was not met
+	ConditionFailed bool
+	// ItemCount the number of items returned by the request
+	ItemCount int
+	// ConsumedCapacity the capacity consumed by the request, batch requests have an entry per table
+	ConsumedCapacity []*dynamodb.ConsumedCapacity
+}
+
+// OperationInfo describes a dynastore operation, such as Get or Scan, which may send more than one request,
+// this is passed to the OperationStarted and OperationCompleted hooks
+type OperationInfo struct {
+	// Operation the name of the dynastore operation, see OperationName
+	Operation string
+	// TableName the name of the table
+	TableName string
+	// PartitionKey the partition of the operation, this is empty for scans and table operations
+	PartitionKey string
+	// Duration the time taken to complete the operation, this is only set once it has completed
+	Duration time.Duration
+	// Err the error returned by the operation, this is only set once it has completed
+	Err error
+}
+
+// ChainHooks combine the hooks provided into a single set of hooks so a session can, for example, trace, record
+// metrics and log each request. Nil hooks, and hooks with nil callbacks, are skipped.
+//
+// The started and built callbacks are invoked in the order provided, each passed the context returned by the
+// one before it. The completed callbacks are invoked in the reverse order, each passed the context returned
+// by its own started or built callback.
+func ChainHooks(hooks ...*StoreHooks) *StoreHooks {
+	var chain []*StoreHooks
+
+	for _, h := range hooks {
+		if h != nil {
+			chain = append(chain, h)
+		}
+	}
+
+	return &StoreHooks{
+		RequestBuilt: func(ctx context.Context, params interface{}) context.Context {
+			ctxs := make([]context.Context, len(chain))
+
+			for n, h := range chain {
+				if h.RequestBuilt != nil {
+					ctx = h.RequestBuilt(ctx, params)
+				}
+				ctxs[n] = ctx
+			}
+
+			return context.WithValue(ctx, chainRequestKey{}, ctxs)
+		},
+		RequestCompleted: func(ctx context.Context, info *RequestInfo) {
+			ctxs, _ := ctx.Value(chainRequestKey{}).([]context.Context)
+
+			for n := len(chain) - 1; n >= 0; n-- {
+				if chain[n].RequestCompleted != nil {
+					chain[n].RequestCompleted(chainContext(ctx, ctxs, n), info)
+				}
+			}
+		},
+		OperationStarted: func(ctx context.Context, info *OperationInfo) context.Context {
+			ctxs := make([]context.Context, len(chain))
+
+			for n, h := range chain {
+				if h.OperationStarted != nil {
+					ctx = h.OperationStarted(ctx, info)
+				}
+				ctxs[n] = ctx
+			}
+
+			return context.WithValue(ctx, chainOperationKey{}, ctxs)
+		},
+		OperationCompleted: func(ctx context.Context, info *OperationInfo) {
+			ctxs, _ := ctx.Value(chainOperationKey{}).([]context.Context)
+
+			for n := len(chain) - 1; n >= 0; n-- {
+				if chain[n].OperationCompleted != nil {
+					chain[n].OperationCompleted(chainContext(ctx, ctxs, n), info)
+				}
+			}
+		},
+	}
+}
+
+type (
+	chainRequestKey   struct{}
+	chainOperationKey struct{}
+)
+
+// chainContext the context returned by the hook at the index, falling back to the context provided
+func chainContext(ctx context.Context, ctxs []context.Context, n int) context.Context {
+	if n < len(ctxs) && ctxs[n] != nil {
+		return ctxs[n]
+	}
+
+	return ctx
+}
+
+// startOperation assign the operation name and partition key to the context and invoke the operation
+// hooks, the function returned must be called with the error returned by the operation once it completes
+func (ds *DynaSession) startOperation(ctx context.Context, name, tableName, partitionKey string) (context.Context, func(error)) {
+	ctx = setOperationName(ctx, name)
+
+	if partitionKey != "" {
+		ctx = setPartitionKey(ctx, partitionKey)
+	}
+
+	hooks := ds.storeHooks
+	if hooks == nil {
+		hooks = defaultHooks
+	}
+
+	info := &OperationInfo{Operation: name, TableName: tableName, PartitionKey: partitionKey}
+
+	if hooks.OperationStarted != nil {
+		ctx = hooks.OperationStarted(ctx, info)
+	}
+
+	start := time.Now()
+
+	return ctx, func(err error) {
+		if hooks.OperationCompleted == nil {
+			return
+		}
+
+		info.Duration = time.Since(start)
+		info.Err = err
+
+		hooks.OperationCompleted(ctx, info)
+	}
+}
+
+// send a request using the AWS SDK function provided, retrying throttling and transient errors using the
+// retry policy for the operation
+func send[I, O any](ctx context.Context, ds *DynaSession, tableName string, input I, call func(aws.Context, I, ...request.Option) (O, error)) (O, error) {
+	output, err := sendLimited(ctx, ds, tableName, nil, input, call)
+	if err != nil {
+		return output, wrapError(ctx, tableName, "", "", err)
+	}
+
+	return output, nil
+}
+
+// sendTable send a request to the table through the interceptors of the table, waiting for the capacity
+// limiter of the table or index read or written by the request
+func sendTable[I, O any](ctx context.Context, dt *DynaTable, input I, call func(aws.Context, I, ...request.Option) (O, error)) (O, error) {
+	partitionKey, sortKey := dt.inputKey(ctx, input)
+
+	invoker := func(ctx context.Context, op *Operation) (interface{}, error) {
+		in, ok := op.Input.(I)
+		if !ok {
+			return nil, fmt.Errorf("input %T replaced with %T: %w", input, op.Input, ErrInterceptorType)
+		}
+
+		// records read from the cache are returned once they have passed through the interceptors
+		if output, ok := dt.cachedOutput(ctx, in); ok {
+			return output, nil
+		}
+
+		send := func(ctx context.Context) (interface{}, error) {
+			output, err := sendLimited(ctx, dt.session, dt.GetTableName(), dt.limiters.forInput(in), in, call)
+
+			// a write which failed its condition didn't change the record, any other outcome may have
+			if !isConditionFailed(err) {
+				dt.invalidateCaches(in, output)
+			}
+
+			if err != nil {
+				return output, wrapError(ctx, dt.GetTableName(), op.PartitionKey, op.SortKey, err)
+			}
+
+			return output, nil
+		}
+
+		// identical reads share the request once each of them has passed through the interceptors
+		if key, ok := dt.flightKey(ctx, in); ok {
+			return dt.flights.do(ctx, key, send)
+		}
+
+		return send(ctx)
+	}
+
+	res, err := chainInterceptors(dt.interceptors, invoker)(ctx, &Operation{
+		Name:         OperationName(ctx),
+		TableName:    dt.GetTableName(),
+		PartitionKey: partitionKey,
+		SortKey:      sortKey,
+		Input:        input,
+	})
+
+	// a nil result is only valid along with an error
+	output, ok := res.(O)
+	if !ok && (res != nil || err == nil) {
+		return output, fmt.Errorf("result %T expected %T: %w", res, output, ErrInterceptorType)
+	}
+
+	// callers read the output of a successful request so a typed nil is rejected along with a missing result
+	if err == nil && isNilPointer(res) {
+		return output, fmt.Errorf("result nil %T: %w", res, ErrInterceptorType)
+	}
+
+	return output, err
+}
+
+// isNilPointer the value is a typed nil pointer, such as a nil *dynamodb.GetItemOutput
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// inputKey the partition and sort key of the record read or written by the request, falling back to the
+// partition of the operation for requests which read more than one record
+func (dt *DynaTable) inputKey(ctx context.Context, input interface{}) (string, string) {
+	var key map[string]*dynamodb.AttributeValue
+
+	switch v := input.(type) {
+	case *dynamodb.GetItemInput:
+		key = v.Key
+	case *dynamodb.UpdateItemInput:
+		key = v.Key
+	case *dynamodb.DeleteItemInput:
+		key = v.Key
+	}
+
+	if key == nil {
+		return PartitionKey(ctx), ""
+	}
+
+	return aws.StringValue(key[dt.attributes.PartitionKey].S), aws.StringValue(key[dt.attributes.SortKey].S)
+}
+
+func sendLimited[I, O any](ctx context.Context, ds *DynaSession, tableName string, limiter *capacityLimiter, input I, call func(aws.Context, I, ...request.Option) (O, error)) (O, error) {
+	policy := ds.retryPolicy(OperationName(ctx))
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.wait(ctx); err != nil {
+				var output O
+				return output, err
+			}
+		}
+
+		output, err := sendAttempt(ctx, ds, tableName, attempt, input, call)
+
+		if limiter != nil {
+			limiter.consume(capacityUnits(consumedCapacity(output)...))
+		}
+
+		if attempt >= policy.MaxAttempts || !isRetryable(err) {
+			return output, err
+		}
+
+		if serr := sleepWithContext(ctx, policy.delay(attempt)); serr != nil {
+			return output, err
+		}
+	}
+}
+
+// sendAttempt send a request, this is passed to the hooks before it is sent and once it has completed
+func sendAttempt[I, O any](ctx context.Context, ds *DynaSession, tableName string, attempt int, input I, call func(aws.Context, I, ...request.Option) (O, error)) (O, error) {
+	hooks := ds.storeHooks
+	if hooks == nil {
+		hooks = defaultHooks
+	}
+
+	if hooks.RequestBuilt != nil {
+		ctx = hooks.RequestBuilt(ctx, input)
+	}
+
+	if hooks.RequestCompleted == nil {
+		return call(ctx, input)
+	}
+
+	var retries int
+
+	start := time.Now()
+
+	output, err := call(ctx, input, func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			retries = r.RetryCount
+		})
+	})
+
+	hooks.RequestCompleted(ctx, &RequestInfo{
+		Operation:        OperationName(ctx),
+		TableName:        tableName,
+		PartitionKey:     PartitionKey(ctx),
+		IndexName:        indexName(input),
+		Input:            input,
+		Output:           output,
+		Duration:         time.Since(start),
+		Attempt:          attempt,
+		Retries:          retries,
+		Err:              err,
+		ConditionFailed:  isConditionFailed(err),
+		ItemCount:        itemCount(output),
+		ConsumedCapacity: consumedCapacity(output),
+	})
+
+	return output, err
+}
+
+// indexName the name of the index read by a query or scan input
+func indexName(input interface{}) string {
+	switch v := input.(type) {
+	case *dynamodb.QueryInput:
+		return aws.StringValue(v.IndexName)
+	case *dynamodb.ScanInput:
+		return aws.StringValue(v.IndexName)
+	}
+
+	return ""
+}
+
+// isConditionFailed the request failed because the condition expression was not met
+func isConditionFailed(err error) bool {
+	_, ok := conditionFailed(err)
+
+	return ok
+}
+
+// itemCount the number of items returned in the output of a request
+func itemCount(output interface{}) int {
+	switch v := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if v != nil && len(v.Item) != 0 {
+			return 1
+		}
+	case *dynamodb.QueryOutput:
+		if v != nil {
+			return int(aws.Int64Value(v.Count))
+		}
+	case *dynamodb.ScanOutput:
+		if v != nil {
+			return int(aws.Int64Value(v.Count))
+		}
+	}
+
+	return 0
+}
+
+// consumedCapacity the capacity reported in the output of a request
+func consumedCapacity(output interface{}) []*dynamodb.ConsumedCapacity {
+	var cc *dynamodb.ConsumedCapacity
+
+	switch v := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if v != nil {
+			cc = v.ConsumedCapacity
+		}
+	case *dynamodb.UpdateItemOutput:
+		if v != nil {
+			cc = v.ConsumedCapacity
+		}
+	case *dynamodb.DeleteItemOutput:
+		if v != nil {
+			cc = v.ConsumedCapacity
+		}
+	case *dynamodb.QueryOutput:
+		if v != nil {
+			cc = v.ConsumedCapacity
+		}
+	case *dynamodb.ScanOutput:
+		if v != nil {
+			cc = v.ConsumedCapacity
+		}
+	case *dynamodb.BatchWriteItemOutput:
+		if v != nil {
+			return v.ConsumedCapacity
+		}
+	}
+
+	if cc == nil {
+		return nil
+	}
+
+	return []*dynamodb.ConsumedCapacity{cc}
+}
@@ -0,0 +1,312 @@
+package dynastore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func Test_send(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name         string
+		output       *dynamodb.QueryOutput
+		err          error
+		wantCapacity int
+	}{
+		{
+			name:         "should report consumed capacity",
+			output:       &dynamodb.QueryOutput{ConsumedCapacity: &dynamodb.ConsumedCapacity{TableName: aws.String("testing"), CapacityUnits: aws.Float64(1.5)}},
+			wantCapacity: 1,
+		},
+		{
+			name:   "should report error",
+			output: &dynamodb.QueryOutput{},
+			err:    errFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var info *RequestInfo
+
+			ds := NewWithClient(nil, &StoreHooks{
+				RequestCompleted: func(ctx context.Context, ri *RequestInfo) {
+					info = ri
+				},
+			})
+
+			input := &dynamodb.QueryInput{IndexName: aws.String("idx_created")}
+
+			ctx := setOperationName(context.Background(), "ListPage")
+
+			_, err := send(ctx, ds, "testing", input, func(ctx aws.Context, in *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+				return tt.output, tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("send() error = %v, want %v", err, tt.err)
+			}
+
+			if info == nil {
+				t.Fatal("send() RequestCompleted not called")
+			}
+			if info.Operation != "ListPage" || info.TableName != "testing" || info.IndexName != "idx_created" {
+				t.Errorf("send() info = %+v", info)
+			}
+			if !errors.Is(info.Err, tt.err) {
+				t.Errorf("send() info.Err = %v, want %v", info.Err, tt.err)
+			}
+			if len(info.ConsumedCapacity) != tt.wantCapacity {
+				t.Errorf("send() info.ConsumedCapacity = %v, want %d entries", info.ConsumedCapacity, tt.wantCapacity)
+			}
+		})
+	}
+}
+
+func TestDynaSession_operationHooks(t *testing.T) {
+	type operationKey struct{}
+
+	var pages int
+
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		pages++
+
+		// the first page has a last key so the list reads a second page
+		if pages == 1 {
+			return map[string]interface{}{
+				"Items":            []interface{}{},
+				"LastEvaluatedKey": map[string]interface{}{"id": map[string]string{"S": "agent"}, "name": map[string]string{"S": "key1"}},
+			}
+		}
+
+		return map[string]interface{}{"Items": []interface{}{}}
+	})
+
+	var (
+		started, completed []*OperationInfo
+		requests           int
+	)
+
+	ds := NewWithClient(svc, &StoreHooks{
+		OperationStarted: func(ctx context.Context, info *OperationInfo) context.Context {
+			started = append(started, info)
+			return context.WithValue(ctx, operationKey{}, info.Operation)
+		},
+		OperationCompleted: func(ctx context.Context, info *OperationInfo) {
+			completed = append(completed, info)
+		},
+		RequestBuilt: func(ctx context.Context, params interface{}) context.Context {
+			if ctx.Value(operationKey{}) != "List" {
+				t.Error("RequestBuilt() context not derived from OperationStarted")
+			}
+			requests++
+			return ctx
+		},
+	})
+
+	_, err := ds.Table("testing").Partition("agent").List("key")
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("List() error = %v, want ErrKeyNotFound", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+
+	if len(started) != 1 || len(completed) != 1 {
+		t.Fatalf("started = %d, completed = %d, want one of each", len(started), len(completed))
+	}
+
+	info := completed[0]
+
+	if info.Operation != "List" || info.TableName != "testing" || info.PartitionKey != "agent" {
+		t.Errorf("OperationCompleted() info = %+v", info)
+	}
+	if !errors.Is(info.Err, ErrKeyNotFound) {
+		t.Errorf("OperationCompleted() info.Err = %v, want ErrKeyNotFound", info.Err)
+	}
+}
+
+func TestDynaSession_operationHooksUpdate(t *testing.T) {
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		if target == "UpdateItem" {
+			return map[string]interface{}{"Attributes": map[string]interface{}{
+				"id":      map[string]string{"S": "agent"},
+				"name":    map[string]string{"S": "key"},
+				"version": map[string]string{"N": "1"},
+			}}
+		}
+
+		return map[string]interface{}{}
+	})
+
+	var (
+		operations []string
+		requests   []string
+	)
+
+	ds := NewWithClient(svc, &StoreHooks{
+		OperationStarted: func(ctx context.Context, info *OperationInfo) context.Context {
+			operations = append(operations, info.Operation)
+			return ctx
+		},
+		RequestBuilt: func(ctx context.Context, params interface{}) context.Context {
+			requests = append(requests, OperationName(ctx))
+			return ctx
+		},
+	})
+
+	_, err := ds.Table("testing").Partition("agent").Update("key", func(current *KVPair) ([]WriteOption, error) {
+		return []WriteOption{WriteWithString("value")}, nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// the read and the write are both reported as part of the update
+	if !reflect.DeepEqual(operations, []string{"Update"}) {
+		t.Errorf("operations = %v, want [Update]", operations)
+	}
+	if !reflect.DeepEqual(requests, []string{"Update", "Update"}) {
+		t.Errorf("requests = %v, want [Update Update]", requests)
+	}
+}
+
+// testErrorCode returned by the handler of a test client to fail the request with the error code
+type testErrorCode string
+
+// newTestClient create a DynamoDB client which sends requests to a test server, the handler is passed the
+// API target and JSON body of each request and returns the JSON body of the response
+func newTestClient(t *testing.T, handler func(target string, body map[string]interface{}) interface{}) *dynamodb.DynamoDB {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		target := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+		res := handler(target, body)
+
+		if code, ok := res.(testErrorCode); ok {
+			w.WriteHeader(http.StatusBadRequest)
+			res = map[string]string{"__type": "com.amazonaws.dynamodb.v20120810#" + string(code), "message": string(code)}
+		}
+
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Endpoint:    aws.String(srv.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("testing", "testing", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+
+	return dynamodb.New(sess)
+}
+
+func TestChainHooks(t *testing.T) {
+	type hookKey string
+
+	var events []string
+
+	recorder := func(name string) *StoreHooks {
+		key := hookKey(name)
+
+		// each completed callback must be passed the context returned by its own started or built callback
+		check := func(ctx context.Context, event string) {
+			if ctx.Value(key) != event {
+				t.Errorf("%s context value = %v, want %s", name, ctx.Value(key), event)
+			}
+		}
+
+		return &StoreHooks{
+			OperationStarted: func(ctx context.Context, info *OperationInfo) context.Context {
+				events = append(events, name+".OperationStarted")
+				return context.WithValue(ctx, key, "operation")
+			},
+			OperationCompleted: func(ctx context.Context, info *OperationInfo) {
+				check(ctx, "operation")
+				events = append(events, name+".OperationCompleted")
+			},
+			RequestBuilt: func(ctx context.Context, params interface{}) context.Context {
+				events = append(events, name+".RequestBuilt")
+				return context.WithValue(ctx, key, "request")
+			},
+			RequestCompleted: func(ctx context.Context, info *RequestInfo) {
+				check(ctx, "request")
+				events = append(events, name+".RequestCompleted")
+			},
+		}
+	}
+
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		return map[string]interface{}{}
+	})
+
+	hooks := ChainHooks(recorder("tracing"), nil, &StoreHooks{}, recorder("logging"))
+
+	_, err := NewWithClient(svc, hooks).Table("testing").GetWithContext(context.Background(), "agent", "key")
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("GetWithContext() error = %v, want ErrKeyNotFound", err)
+	}
+
+	want := []string{
+		"tracing.OperationStarted",
+		"logging.OperationStarted",
+		"tracing.RequestBuilt",
+		"logging.RequestBuilt",
+		"logging.RequestCompleted",
+		"tracing.RequestCompleted",
+		"logging.OperationCompleted",
+		"tracing.OperationCompleted",
+	}
+
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestNewWithClient_hooks(t *testing.T) {
+	optionHooks := &StoreHooks{}
+	argHooks := &StoreHooks{}
+
+	tests := []struct {
+		name       string
+		storeHooks *StoreHooks
+		options    []SessionOption
+		want       *StoreHooks
+	}{
+		{name: "should use the hooks provided", storeHooks: argHooks, want: argHooks},
+		{name: "should prefer the hooks provided over the options", storeHooks: argHooks, options: []SessionOption{SessionWithAWSHooks(optionHooks)}, want: argHooks},
+		{name: "should use the hooks in the options when nil", options: []SessionOption{SessionWithAWSHooks(optionHooks)}, want: optionHooks},
+		{name: "should default the hooks when nil", want: defaultHooks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewWithClient(nil, tt.storeHooks, tt.options...).storeHooks; got != tt.want {
+				t.Errorf("NewWithClient() hooks = %p, want %p", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package dynastore
+
+import (
+	"fmt"
+)
+
+// resolveIndex look up the index named in the read options in the indexes registered with the table
+func (dt *DynaTable) resolveIndex(readOptions *ReadOptions) error {
+	if readOptions.indexName == "" {
+		return nil
+	}
+
+	idx, ok := dt.indexes[readOptions.indexName]
+	if !ok {
+		return fmt.Errorf("index %s not registered with table %s: %w", readOptions.indexName, dt.tableName, ErrUnknownIndex)
+	}
+
+	readOptions.index = idx
+
+	return nil
+}
+
+// validateIndexFields check the fields being written contain the key attributes for each of the indexes
+// the record claims to belong to
+func (dt *DynaTable) validateIndexFields(writeOptions *WriteOptions) error {
+	for _, name := range writeOptions.indexes {
+		idx, ok := dt.indexes[name]
+		if !ok {
+			return fmt.Errorf("index %s not registered with table %s: %w", name, dt.tableName, ErrUnknownIndex)
+		}
+
+		for _, attr := range idx.keyAttributes() {
+			// the table key attributes are always written
+			if dt.attributes.isKey(attr) {
+				continue
+			}
+
+			if _, ok := writeOptions.fields[attr]; !ok {
+				return fmt.Errorf("index %s requires field %s: %w", name, attr, ErrIndexKeyMissing)
+			}
+		}
+	}
+
+	return nil
+}
+
+// keyAttributes the attributes which make up the key of the index
+func (idx *index) keyAttributes() []string {
+	var attrs []string
+
+	if idx.indexType == indexTypeGlobal && idx.partitionKeyAttribute != "" {
+		attrs = append(attrs, idx.partitionKeyAttribute)
+	}
+
+	if idx.sortKeyAttribute != "" {
+		attrs = append(attrs, idx.sortKeyAttribute)
+	}
+
+	return attrs
+}
@@ -0,0 +1,81 @@
+package dynastore
+
+import (
+	"errors"
+	"testing"
+)
+
+func testIndexTable() *DynaTable {
+	return (&DynaSession{}).Table("testing",
+		TableWithLocalIndex("idx_created", "created"),
+		TableWithGlobalIndex("idx_global_1", "pk1", "sk1"),
+		TableWithGlobalIndex("idx_by_name", DefaultSortKeyAttribute, "created"),
+	)
+}
+
+func TestDynaTable_resolveIndex(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []ReadOption
+		wantIndexName string
+		wantErr       error
+	}{
+		{name: "should read without index"},
+		{name: "should resolve registered index", options: []ReadOption{ReadWithIndex("idx_global_1")}, wantIndexName: "idx_global_1"},
+		{name: "should reject unknown index", options: []ReadOption{ReadWithIndex("idx_typo")}, wantErr: ErrUnknownIndex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readOptions := NewReadOptions(tt.options...)
+
+			err := testIndexTable().resolveIndex(readOptions)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("resolveIndex() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantIndexName != "" && readOptions.index.name != tt.wantIndexName {
+				t.Errorf("resolveIndex() index = %v, want %v", readOptions.index.name, tt.wantIndexName)
+			}
+		})
+	}
+}
+
+func TestDynaTable_validateIndexFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []WriteOption
+		wantErr error
+	}{
+		{
+			name:    "should accept complete local index fields",
+			options: []WriteOption{WriteWithFields(map[string]string{"created": "20200103T1100Z"}), WriteWithIndexes("idx_created")},
+		},
+		{
+			name:    "should accept complete global index fields",
+			options: []WriteOption{WriteWithFields(map[string]string{"pk1": "wolfeidau", "sk1": "20200103T1100Z"}), WriteWithIndexes("idx_global_1")},
+		},
+		{
+			name:    "should accept table key attributes used by an index",
+			options: []WriteOption{WriteWithFields(map[string]string{"created": "20200103T1100Z"}), WriteWithIndexes("idx_by_name")},
+		},
+		{
+			name:    "should reject missing global index sort key",
+			options: []WriteOption{WriteWithFields(map[string]string{"pk1": "wolfeidau"}), WriteWithIndexes("idx_created", "idx_global_1")},
+			wantErr: ErrIndexKeyMissing,
+		},
+		{
+			name:    "should reject unknown index",
+			options: []WriteOption{WriteWithIndexes("idx_typo")},
+			wantErr: ErrUnknownIndex,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testIndexTable().validateIndexFields(NewWriteOptions(tt.options...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateIndexFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wolfeidau/dynastore"
+)
+
+func testDeletePrefix(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	kv := dSession.Table("testing-locks").Partition("deleteprefix")
+
+	for i := 1; i <= 30; i++ {
+		err := kv.Put("folder/key"+strconv.Itoa(i), dynastore.WriteWithString("value"))
+		assert.NoError(err)
+	}
+
+	err := kv.Put("other/key", dynastore.WriteWithString("value"))
+	assert.NoError(err)
+
+	t.Run("DeletePrefixDryRun", func(t *testing.T) {
+		progress, err := kv.DeletePrefixWithContext(context.TODO(), "folder/", dynastore.DeleteWithDryRun())
+		assert.NoError(err)
+		assert.True(progress.DryRun)
+		assert.Equal(30, progress.Deleted)
+
+		page, err := kv.ListPage("folder/")
+		assert.NoError(err)
+		assert.Len(page.Keys, 30)
+	})
+
+	t.Run("DeletePrefixResume", func(t *testing.T) {
+		progress, err := kv.DeletePrefix("folder/", dynastore.DeleteWithPageSize(10), dynastore.DeleteWithDryRun())
+		assert.NoError(err)
+		assert.Equal(30, progress.Deleted)
+
+		var reported []*dynastore.DeleteProgress
+
+		progress, err = kv.DeletePrefix("folder/", dynastore.DeleteWithPageSize(10), dynastore.DeleteWithProgress(func(progress *dynastore.DeleteProgress) {
+			p := *progress
+			reported = append(reported, &p)
+		}))
+		assert.NoError(err)
+		assert.Equal(30, progress.Deleted)
+		assert.Empty(progress.LastKey)
+		assert.GreaterOrEqual(len(reported), 3)
+		assert.NotEmpty(reported[0].LastKey)
+
+		// resuming from an earlier page finds nothing left to delete
+		progress, err = kv.DeletePrefix("folder/", dynastore.DeleteWithStartKey(reported[0].LastKey))
+		assert.NoError(err)
+		assert.Equal(0, progress.Deleted)
+
+		page, err := kv.ListPage("")
+		assert.NoError(err)
+		assert.Len(page.Keys, 1)
+		assert.Equal("other/key", page.Keys[0].Key)
+	})
+}
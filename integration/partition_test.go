@@ -2,15 +2,15 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
 	"github.com/wolfeidau/dynastore"
@@ -28,9 +28,6 @@ type globalIndexFields struct {
 func Test(t *testing.T) {
 	assert := require.New(t)
 
-	err := ensureVersionTable(dbSvc, "testing-locks")
-	assert.NoError(err)
-
 	dl := dynastore.NewWithClient(dbSvc, &dynastore.StoreHooks{
 		RequestBuilt: func(ctx context.Context, params interface{}) context.Context {
 			log.Info().Fields(map[string]interface{}{
@@ -41,6 +38,10 @@ func Test(t *testing.T) {
 		},
 	})
 
+	err := dl.EnsureTable(context.TODO(), versionTableSchema("testing-locks"))
+	assert.NoError(err)
+
+	testEnsureTable(t, dl)
 	testPutGetDeleteExists(t, dl)
 	testList(t, dl)
 	testListPage(t, dl)
@@ -48,84 +49,74 @@ func Test(t *testing.T) {
 	testAtomicPutLocalIndex(t, dl)
 	testAtomicPutGlobalIndex(t, dl)
 	testAtomicDelete(t, dl)
+	testScan(t, dl)
+	testDeletePrefix(t, dl)
+	testReadWithIndex(t, dl)
+	testAttributeNames(t, dl)
+	testPartialUpdate(t, dl)
+	testPutKVDeleteKV(t, dl)
+	testUpdate(t, dl)
+	testTouch(t, dl)
+	testExpiryFiltering(t, dl)
+	testClock(t)
+	testRequestCompleted(t)
+	testErrors(t, dl)
+	testSignedPageTokens(t)
 }
 
-func ensureVersionTable(dbSvc dynamodbiface.DynamoDBAPI, tableName string) error {
-	_, err := dbSvc.CreateTable(&dynamodb.CreateTableInput{
-		TableName: aws.String(tableName),
-		KeySchema: []*dynamodb.KeySchemaElement{
-			{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
-			{AttributeName: aws.String("name"), KeyType: aws.String(dynamodb.KeyTypeRange)},
-		},
-		LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndex{
-			{
-				IndexName: aws.String("idx_created"),
-				KeySchema: []*dynamodb.KeySchemaElement{
-					{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
-					{AttributeName: aws.String("created"), KeyType: aws.String(dynamodb.KeyTypeRange)},
-				},
-				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
-			},
-		},
-		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
-			{
-				IndexName: aws.String("idx_global_1"),
-				KeySchema: []*dynamodb.KeySchemaElement{
-					{AttributeName: aws.String("pk1"), KeyType: aws.String(dynamodb.KeyTypeHash)},
-					{AttributeName: aws.String("sk1"), KeyType: aws.String(dynamodb.KeyTypeRange)},
-				},
-				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
-				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-					ReadCapacityUnits:  aws.Int64(1),
-					WriteCapacityUnits: aws.Int64(1),
-				},
-			},
-		},
-		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-			{AttributeName: aws.String("id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
-			{AttributeName: aws.String("name"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
-			{AttributeName: aws.String("created"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
-			{AttributeName: aws.String("pk1"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
-			{AttributeName: aws.String("sk1"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
-		},
-		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-			ReadCapacityUnits:  aws.Int64(1),
-			WriteCapacityUnits: aws.Int64(1),
-		},
-		SSESpecification: &dynamodb.SSESpecification{
-			Enabled: aws.Bool(true),
-			SSEType: aws.String(dynamodb.SSETypeAes256),
-		},
-	})
+func versionTableSchema(tableName string) *dynastore.TableSchema {
+	schema := dynastore.NewTableSchema(tableName)
 
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			if aerr.Code() == dynamodb.ErrCodeResourceInUseException {
-				return nil
-			}
-		}
-		return err
+	schema.BillingMode = dynamodb.BillingModeProvisioned
+	schema.ProvisionedThroughput = &dynastore.Throughput{ReadCapacityUnits: 1, WriteCapacityUnits: 1}
+	schema.LocalIndexes = []dynastore.LocalIndexSchema{
+		{Name: "idx_created", SortKey: dynastore.KeyAttribute{Name: "created"}},
 	}
-
-	err = dbSvc.WaitUntilTableExists(&dynamodb.DescribeTableInput{
-		TableName: aws.String(tableName),
-	})
-	if err != nil {
-		return err
-	}
-
-	_, err = dbSvc.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
-		TableName: aws.String(tableName),
-		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
-			AttributeName: aws.String("expires"),
-			Enabled:       aws.Bool(true),
+	schema.GlobalIndexes = []dynastore.GlobalIndexSchema{
+		{
+			Name:                  "idx_global_1",
+			PartitionKey:          dynastore.KeyAttribute{Name: "pk1"},
+			SortKey:               dynastore.KeyAttribute{Name: "sk1"},
+			ProvisionedThroughput: &dynastore.Throughput{ReadCapacityUnits: 1, WriteCapacityUnits: 1},
 		},
-	})
-	if err != nil {
-		return err
 	}
 
-	return nil
+	return schema
+}
+
+func testEnsureTable(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	t.Run("EnsureTable", func(t *testing.T) {
+		// ensuring an existing table which matches is a no-op
+		err := dSession.EnsureTable(context.TODO(), versionTableSchema("testing-locks"))
+		assert.NoError(err)
+
+		// a missing global index is added to the existing table
+		schema := versionTableSchema("testing-ensure")
+		schema.GlobalIndexes = nil
+
+		err = dSession.EnsureTable(context.TODO(), schema)
+		assert.NoError(err)
+
+		err = dSession.EnsureTable(context.TODO(), versionTableSchema("testing-ensure"))
+		assert.NoError(err)
+
+		res, err := dSession.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("testing-ensure")})
+		assert.NoError(err)
+		assert.Len(res.Table.GlobalSecondaryIndexes, 1)
+
+		// changing the key attributes conflicts with the existing table
+		schema = versionTableSchema("testing-ensure")
+		schema.PartitionKey = dynastore.KeyAttribute{Name: "pk"}
+
+		err = dSession.EnsureTable(context.TODO(), schema)
+		assert.ErrorIs(err, dynastore.ErrSchemaConflict)
+
+		var conflict *dynastore.SchemaConflictError
+		assert.ErrorAs(err, &conflict)
+		assert.NotEmpty(conflict.Differences)
+	})
 }
 
 func testPutGetDeleteExists(t *testing.T, dSession *dynastore.DynaSession) {
@@ -225,9 +216,15 @@ func testAtomicPut(t *testing.T, dSession *dynastore.DynaSession) {
 
 		// This CAS should fail: previous exists.
 		success, _, err := kv.AtomicPut(key, dynastore.WriteWithString("WORLD"))
-		assert.Error(err)
+		assert.ErrorIs(err, dynastore.ErrKeyExists)
 		assert.False(success)
 
+		// The conflict should carry the current record
+		var conflict *dynastore.ConflictError
+		assert.ErrorAs(err, &conflict)
+		assert.Equal(pair.Version, conflict.Current.Version)
+		assert.Equal(value, conflict.Current.BytesValue())
+
 		// This CAS should succeed
 		success, _, err = kv.AtomicPut(key, dynastore.WriteWithPreviousKV(pair), dynastore.WriteWithBytes([]byte("WORLD")))
 		assert.NoError(err)
@@ -236,8 +233,11 @@ func testAtomicPut(t *testing.T, dSession *dynastore.DynaSession) {
 		// This CAS should fail, key has wrong index.
 		pair.Version = 6744
 		success, _, err = kv.AtomicPut(key, dynastore.WriteWithPreviousKV(pair), dynastore.WriteWithBytes([]byte("WORLDWORLD")))
-		assert.Equal(err, dynastore.ErrKeyModified)
+		assert.ErrorIs(err, dynastore.ErrKeyModified)
 		assert.False(success)
+
+		assert.ErrorAs(err, &conflict)
+		assert.Equal([]byte("WORLD"), conflict.Current.BytesValue())
 	})
 }
 
@@ -340,6 +340,42 @@ func testAtomicDelete(t *testing.T, dSession *dynastore.DynaSession) {
 		success, err = kv.AtomicDelete(key, pair)
 		assert.Equal(dynastore.ErrKeyNotFound, err)
 		assert.False(success)
+
+		// Delete a non-existent key without a previous record; should fail
+		success, err = kv.AtomicDelete(key, nil)
+		assert.Equal(dynastore.ErrKeyNotFound, err)
+		assert.False(success)
+	})
+
+	t.Run("AtomicDeleteOutcomes", func(t *testing.T) {
+		key := "testAtomicDeleteOutcomes"
+
+		pair, err := kv.PutKV(key, dynastore.WriteWithBytes([]byte("world")))
+		assert.NoError(err)
+
+		// AtomicDelete with a stale version should report the current record
+		stale := *pair
+		stale.Version = 6744
+		success, err := kv.AtomicDelete(key, &stale)
+		assert.ErrorIs(err, dynastore.ErrKeyModified)
+		assert.False(success)
+
+		var conflict *dynastore.ConflictError
+		assert.ErrorAs(err, &conflict)
+		assert.Equal(pair.Version, conflict.Current.Version)
+
+		// AtomicDelete without a previous record should delete any version
+		success, err = kv.AtomicDelete(key, nil)
+		assert.NoError(err)
+		assert.True(success)
+
+		// AtomicDelete of an expired record should fail
+		pair, err = kv.PutKV(key, dynastore.WriteWithBytes([]byte("world")), dynastore.WriteWithTTL(-time.Minute))
+		assert.NoError(err)
+
+		success, err = kv.AtomicDelete(key, pair)
+		assert.Equal(dynastore.ErrKeyExpired, err)
+		assert.False(success)
 	})
 }
 
@@ -424,3 +460,411 @@ func testListPage(t *testing.T, dSession *dynastore.DynaSession) {
 		assert.Equal(1, len(page.Keys))
 	})
 }
+
+func testReadWithIndex(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	tbl := dSession.Table("testing-locks", dynastore.TableWithSchemaIndexes(versionTableSchema("testing-locks")))
+
+	t.Run("ReadWithIndex", func(t *testing.T) {
+		kv := tbl.Partition("indexed")
+
+		username := "indexed-user"
+		timeStamp := "20200104T1100Z"
+
+		// Put should fail as the global index sort key is missing
+		err := kv.Put("testReadWithIndex", dynastore.WriteWithBytes([]byte("world")), dynastore.WriteWithFields(map[string]string{
+			"pk1": username,
+		}), dynastore.WriteWithIndexes("idx_global_1"))
+		assert.ErrorIs(err, dynastore.ErrIndexKeyMissing)
+
+		err = kv.Put("testReadWithIndex", dynastore.WriteWithBytes([]byte("world")), dynastore.WriteWithFields(map[string]string{
+			"pk1":     username,
+			"sk1":     timeStamp,
+			"created": timeStamp,
+		}), dynastore.WriteWithIndexes("idx_global_1", "idx_created"))
+		assert.NoError(err)
+
+		page, err := kv.ListPage(timeStamp, dynastore.ReadWithIndex("idx_created"))
+		assert.NoError(err)
+		assert.Equal(1, len(page.Keys))
+
+		page, err = tbl.ListPageWithContext(context.TODO(), username, timeStamp, dynastore.ReadWithIndex("idx_global_1"))
+		assert.NoError(err)
+		assert.Equal(1, len(page.Keys))
+
+		_, err = kv.ListPage(timeStamp, dynastore.ReadWithIndex("idx_typo"))
+		assert.ErrorIs(err, dynastore.ErrUnknownIndex)
+	})
+}
+
+func testAttributeNames(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	t.Run("AttributeNames", func(t *testing.T) {
+		schema := dynastore.NewTableSchema("testing-custom")
+		schema.PartitionKey = dynastore.KeyAttribute{Name: "pk"}
+		schema.SortKey = dynastore.KeyAttribute{Name: "sk"}
+		schema.TTLAttribute = "ttl"
+
+		err := dSession.EnsureTable(context.TODO(), schema)
+		assert.NoError(err)
+
+		kv := dSession.Table("testing-custom", dynastore.TableWithAttributeNames(dynastore.AttributeNames{
+			PartitionKey: "pk",
+			SortKey:      "sk",
+			Expires:      "ttl",
+			Payload:      "data",
+		})).Partition("agent")
+
+		err = kv.Put("testAttributeNames", dynastore.WriteWithString("value"), dynastore.WriteWithTTL(time.Minute))
+		assert.NoError(err)
+
+		pair, err := kv.Get("testAttributeNames")
+		assert.NoError(err)
+		assert.Equal("agent", pair.Partition)
+		assert.Equal("testAttributeNames", pair.Key)
+		assert.Equal("value", pair.StringValue())
+		assert.NotEqual(int64(0), pair.Expires)
+
+		res, err := dSession.GetItem(&dynamodb.GetItemInput{
+			TableName: aws.String("testing-custom"),
+			Key: map[string]*dynamodb.AttributeValue{
+				"pk": {S: aws.String("agent")},
+				"sk": {S: aws.String("testAttributeNames")},
+			},
+		})
+		assert.NoError(err)
+		assert.Equal("value", aws.StringValue(res.Item["data"].S))
+		assert.NotNil(res.Item["ttl"])
+	})
+}
+
+func testPartialUpdate(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	tbl := dSession.Table("testing-locks")
+	kv := tbl.Partition("partial")
+
+	t.Run("PartialUpdate", func(t *testing.T) {
+		key := "testPartialUpdate"
+		username := "partial-user"
+		timeStamp := "20200105T1100Z"
+
+		err := kv.Put(key, dynastore.WriteWithString("value"), dynastore.WriteWithTTL(time.Hour), dynastore.WriteWithFields(map[string]string{
+			"pk1": username,
+			"sk1": timeStamp,
+		}))
+		assert.NoError(err)
+
+		page, err := tbl.ListPageWithContext(context.TODO(), username, timeStamp, dynastore.ReadWithGlobalIndex("idx_global_1", "pk1", "sk1"))
+		assert.NoError(err)
+		assert.Equal(1, len(page.Keys))
+
+		// remove the record from the sparse index and clear the expiry leaving the payload untouched
+		err = kv.Put(key, dynastore.WriteWithRemoveFields("pk1", "sk1"), dynastore.WriteWithRemoveExpires(), dynastore.WriteWithoutPayload())
+		assert.NoError(err)
+
+		page, err = tbl.ListPageWithContext(context.TODO(), username, timeStamp, dynastore.ReadWithGlobalIndex("idx_global_1", "pk1", "sk1"))
+		assert.NoError(err)
+		assert.Equal(0, len(page.Keys))
+
+		pair, err := kv.Get(key)
+		assert.NoError(err)
+		assert.Equal("value", pair.StringValue())
+		assert.Equal(int64(0), pair.Expires)
+
+		fields := map[string]string{}
+		assert.NoError(pair.DecodeFields(&fields))
+		assert.Empty(fields)
+	})
+}
+
+func testPutKVDeleteKV(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	kv := dSession.Table("testing-locks").Partition("putkv")
+
+	t.Run("PutKVDeleteKV", func(t *testing.T) {
+		key := "testPutKVDeleteKV"
+
+		pair, err := kv.PutKV(key, dynastore.WriteWithString("first"))
+		assert.NoError(err)
+		assert.Equal("first", pair.StringValue())
+		assert.Equal(int64(1), pair.Version)
+
+		pair, err = kv.PutKV(key, dynastore.WriteWithString("second"))
+		assert.NoError(err)
+		assert.Equal("second", pair.StringValue())
+		assert.Equal(int64(2), pair.Version)
+
+		existed, previous, err := kv.DeleteKV(key)
+		assert.NoError(err)
+		assert.True(existed)
+		assert.Equal("second", previous.StringValue())
+		assert.Equal(int64(2), previous.Version)
+
+		existed, previous, err = kv.DeleteKV(key)
+		assert.NoError(err)
+		assert.False(existed)
+		assert.Nil(previous)
+	})
+}
+
+func testUpdate(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	kv := dSession.Table("testing-locks").Partition("update")
+
+	t.Run("Update", func(t *testing.T) {
+		key := "testUpdate"
+
+		increment := func(current *dynastore.KVPair) ([]dynastore.WriteOption, error) {
+			count := 0
+			if current != nil {
+				count, _ = strconv.Atoi(current.StringValue())
+			}
+			return []dynastore.WriteOption{dynastore.WriteWithString(strconv.Itoa(count + 1))}, nil
+		}
+
+		// concurrent increments should all be applied, with the first creating the record
+		var wg sync.WaitGroup
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := kv.UpdateWithContext(context.TODO(), key, increment, dynastore.UpdateWithMaxAttempts(20))
+				assert.NoError(err)
+			}()
+		}
+
+		wg.Wait()
+
+		pair, err := kv.Get(key)
+		assert.NoError(err)
+		assert.Equal("5", pair.StringValue())
+
+		// the callback can abort the update
+		errAbort := errors.New("abort")
+		_, err = kv.Update(key, func(current *dynastore.KVPair) ([]dynastore.WriteOption, error) {
+			return nil, errAbort
+		})
+		assert.ErrorIs(err, errAbort)
+
+		_, err = kv.Update("testUpdate/missing", increment, dynastore.UpdateWithoutCreate())
+		assert.ErrorIs(err, dynastore.ErrKeyNotFound)
+	})
+}
+
+func testTouch(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	kv := dSession.Table("testing-locks").Partition("touch")
+
+	t.Run("Touch", func(t *testing.T) {
+		key := "testTouch"
+		expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+
+		pair, err := kv.PutKV(key, dynastore.WriteWithString("value"), dynastore.WriteWithExpiresAt(expiresAt))
+		assert.NoError(err)
+
+		got, ok := pair.ExpiresAt()
+		assert.True(ok)
+		assert.True(expiresAt.Equal(got))
+
+		// Touch should extend the TTL without modifying the payload
+		touched, err := kv.TouchWithContext(context.TODO(), key, time.Hour, pair)
+		assert.NoError(err)
+		assert.Equal("value", touched.StringValue())
+		assert.Equal(pair.Version+1, touched.Version)
+
+		remaining, ok := touched.TTLRemaining()
+		assert.True(ok)
+		assert.Greater(remaining, 50*time.Minute)
+
+		// Touch with a stale version should fail
+		_, err = kv.Touch(key, time.Hour, pair)
+		assert.ErrorIs(err, dynastore.ErrKeyModified)
+
+		// Touch of a missing key should fail
+		_, err = kv.Touch("testTouch/missing", time.Hour, nil)
+		assert.ErrorIs(err, dynastore.ErrKeyNotFound)
+	})
+}
+
+func testExpiryFiltering(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	kv := dSession.Table("testing-locks").Partition("expiry")
+
+	t.Run("ExpiryFiltering", func(t *testing.T) {
+		err := kv.Put("testExpiry/live", dynastore.WriteWithString("live"))
+		assert.NoError(err)
+
+		err = kv.Put("testExpiry/expired", dynastore.WriteWithString("expired"), dynastore.WriteWithTTL(-time.Minute))
+		assert.NoError(err)
+
+		_, err = kv.Get("testExpiry/expired")
+		assert.ErrorIs(err, dynastore.ErrKeyNotFound)
+
+		pair, err := kv.Get("testExpiry/expired", dynastore.ReadIncludeExpired())
+		assert.NoError(err)
+		assert.Equal("expired", pair.StringValue())
+
+		exists, err := kv.Exists("testExpiry/expired", dynastore.ReadIncludeExpired())
+		assert.NoError(err)
+		assert.True(exists)
+
+		page, err := kv.ListPage("testExpiry/")
+		assert.NoError(err)
+		assert.Len(page.Keys, 1)
+		assert.Equal("testExpiry/live", page.Keys[0].Key)
+
+		page, err = kv.ListPage("testExpiry/", dynastore.ReadIncludeExpired())
+		assert.NoError(err)
+		assert.Len(page.Keys, 2)
+
+		pairs, err := kv.List("testExpiry/")
+		assert.NoError(err)
+		assert.Len(pairs, 1)
+
+		var keys []string
+
+		err = dSession.Table("testing-locks").Scan(context.TODO(), func(page *dynastore.ScanPage) error {
+			for _, pair := range page.Keys {
+				keys = append(keys, pair.Key)
+			}
+			return nil
+		})
+		assert.NoError(err)
+		assert.Contains(keys, "testExpiry/live")
+		assert.NotContains(keys, "testExpiry/expired")
+	})
+}
+
+func testClock(t *testing.T) {
+	assert := require.New(t)
+
+	clock := dynastore.NewFakeClock(time.Now())
+
+	dSession := dynastore.NewWithClient(dbSvc, &dynastore.StoreHooks{
+		RequestBuilt: func(ctx context.Context, params interface{}) context.Context { return ctx },
+	}, dynastore.SessionWithClock(clock))
+
+	kv := dSession.Table("testing-locks").Partition("clock")
+
+	t.Run("Clock", func(t *testing.T) {
+		key := "testClock"
+
+		pair, err := kv.PutKV(key, dynastore.WriteWithString("value"), dynastore.WriteWithTTL(time.Minute))
+		assert.NoError(err)
+		assert.Equal(clock.Now().Add(time.Minute).Unix(), pair.Expires)
+
+		_, err = kv.Get(key)
+		assert.NoError(err)
+
+		// move past the TTL without waiting
+		clock.Advance(2 * time.Minute)
+
+		_, err = kv.Get(key)
+		assert.ErrorIs(err, dynastore.ErrKeyNotFound)
+
+		// an expired record can be replaced by a create
+		created, _, err := kv.AtomicPut(key, dynastore.WriteWithString("replaced"))
+		assert.NoError(err)
+		assert.True(created)
+	})
+}
+
+func testRequestCompleted(t *testing.T) {
+	assert := require.New(t)
+
+	var infos []*dynastore.RequestInfo
+
+	dSession := dynastore.NewWithClient(dbSvc, &dynastore.StoreHooks{
+		RequestCompleted: func(ctx context.Context, info *dynastore.RequestInfo) {
+			infos = append(infos, info)
+		},
+	})
+
+	kv := dSession.Table("testing-locks").Partition("hooks")
+
+	t.Run("RequestCompleted", func(t *testing.T) {
+		err := kv.Put("testRequestCompleted", dynastore.WriteWithString("value"))
+		assert.NoError(err)
+
+		_, err = kv.Get("testRequestCompleted")
+		assert.NoError(err)
+
+		_, err = kv.Get("testRequestCompleted/missing")
+		assert.ErrorIs(err, dynastore.ErrKeyNotFound)
+
+		assert.Len(infos, 3)
+
+		assert.Equal("Put", infos[0].Operation)
+		assert.Equal("Get", infos[1].Operation)
+
+		for _, info := range infos {
+			assert.Equal("testing-locks", info.TableName)
+			assert.NoError(info.Err)
+			assert.NotEmpty(info.ConsumedCapacity)
+			assert.Greater(info.Duration, time.Duration(0))
+		}
+	})
+}
+
+func testErrors(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := dSession.Table("testing-missing").Partition("errors").Get("testErrors")
+		assert.ErrorIs(err, dynastore.ErrTableNotFound)
+
+		var dErr *dynastore.Error
+		assert.ErrorAs(err, &dErr)
+		assert.Equal("Get", dErr.Op)
+		assert.Equal("testing-missing", dErr.Table)
+		assert.Equal("errors", dErr.PartitionKey)
+		assert.Equal("testErrors", dErr.SortKey)
+	})
+}
+
+func testSignedPageTokens(t *testing.T) {
+	assert := require.New(t)
+
+	clock := dynastore.NewFakeClock(time.Now())
+
+	dSession := dynastore.NewWithClient(dbSvc, &dynastore.StoreHooks{
+		RequestBuilt: func(ctx context.Context, params interface{}) context.Context { return ctx },
+	}, dynastore.SessionWithClock(clock), dynastore.SessionWithPageTokenKey([]byte("testing-page-token-key-32-bytes!"), time.Minute))
+
+	kv := dSession.Table("testing-locks").Partition("signed")
+
+	t.Run("SignedPageTokens", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			err := kv.Put("testSigned/key"+strconv.Itoa(i), dynastore.WriteWithString("value"))
+			assert.NoError(err)
+		}
+
+		page, err := kv.ListPage("testSigned/", dynastore.ReadWithLimit(2))
+		assert.NoError(err)
+		assert.NotEmpty(page.LastKey)
+
+		next, err := kv.ListPage("testSigned/", dynastore.ReadWithLimit(2), dynastore.ReadWithStartKey(page.LastKey))
+		assert.NoError(err)
+		assert.Len(next.Keys, 1)
+
+		// the token is bound to the partition and prefix it came from
+		_, err = dSession.Table("testing-locks").Partition("agent").ListPage("testSigned/", dynastore.ReadWithStartKey(page.LastKey))
+		assert.ErrorIs(err, dynastore.ErrInvalidPageToken)
+
+		_, err = kv.ListPage("testSigned/key", dynastore.ReadWithStartKey(page.LastKey))
+		assert.ErrorIs(err, dynastore.ErrInvalidPageToken)
+
+		clock.Advance(2 * time.Minute)
+
+		_, err = kv.ListPage("testSigned/", dynastore.ReadWithStartKey(page.LastKey))
+		assert.ErrorIs(err, dynastore.ErrInvalidPageToken)
+	})
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfeidau/dynastore"
+)
+
+func testScan(t *testing.T, dSession *dynastore.DynaSession) {
+	assert := require.New(t)
+
+	tbl := dSession.Table("testing-locks")
+	kv := tbl.Partition("scan")
+
+	for i := 1; i <= 10; i++ {
+		err := kv.Put("testScan/key"+strconv.Itoa(i), dynastore.WriteWithString("value"), dynastore.WriteWithFields(map[string]string{
+			"category": "scanned",
+		}))
+		assert.NoError(err)
+	}
+
+	onlyScanPartition := dexp.Name(dynastore.DefaultPartitionKeyAttribute).Equal(dexp.Value("scan"))
+
+	t.Run("Scan", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			keys     = map[string]bool{}
+			segments = map[int]bool{}
+		)
+
+		err := tbl.Scan(context.TODO(), func(page *dynastore.ScanPage) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			segments[page.Segment] = true
+
+			for _, kv := range page.Keys {
+				keys[kv.Key] = true
+				assert.Equal("value", kv.StringValue())
+			}
+
+			return nil
+		}, dynastore.ScanWithTotalSegments(4), dynastore.ScanWithFilter(onlyScanPartition))
+		assert.NoError(err)
+		assert.Len(keys, 10)
+		assert.Len(segments, 4)
+	})
+
+	t.Run("ScanProjection", func(t *testing.T) {
+		err := tbl.Scan(context.TODO(), func(page *dynastore.ScanPage) error {
+			for _, kv := range page.Keys {
+				assert.Equal("scan", kv.Partition)
+				assert.Equal("", kv.StringValue())
+
+				fields := map[string]string{}
+				assert.NoError(kv.DecodeFields(&fields))
+				assert.Equal("scanned", fields["category"])
+			}
+
+			return nil
+		}, dynastore.ScanWithFilter(onlyScanPartition), dynastore.ScanWithProjection("category"))
+		assert.NoError(err)
+	})
+
+	t.Run("ScanResume", func(t *testing.T) {
+		errStop := errors.New("stop")
+
+		var (
+			lastKey string
+			count   int
+		)
+
+		err := tbl.Scan(context.TODO(), func(page *dynastore.ScanPage) error {
+			count += len(page.Keys)
+			lastKey = page.LastKey
+			return errStop
+		}, dynastore.ScanWithFilter(onlyScanPartition), dynastore.ScanWithLimit(3))
+		assert.ErrorIs(err, errStop)
+		assert.NotEmpty(lastKey)
+
+		err = tbl.Scan(context.TODO(), func(page *dynastore.ScanPage) error {
+			count += len(page.Keys)
+			return nil
+		}, dynastore.ScanWithFilter(onlyScanPartition), dynastore.ScanWithLimit(3), dynastore.ScanWithStartKey(0, lastKey), dynastore.ScanWithReadCapacityLimit(100))
+		assert.NoError(err)
+		assert.Equal(10, count)
+	})
+
+	t.Run("ScanInvalidSegments", func(t *testing.T) {
+		err := tbl.Scan(context.TODO(), func(page *dynastore.ScanPage) error {
+			return nil
+		}, dynastore.ScanWithTotalSegments(2), dynastore.ScanWithSegments(2))
+		assert.ErrorIs(err, dynastore.ErrInvalidScanSegments)
+	})
+}
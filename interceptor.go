@@ -0,0 +1,44 @@
+package dynastore
+
+import (
+	"context"
+)
+
+// Operation describes a request sent by a dynastore operation, this is passed to each interceptor
+type Operation struct {
+	// Name the name of the dynastore operation, see OperationName
+	Name string
+	// TableName the name of the table
+	TableName string
+	// PartitionKey the partition key, this is empty for scans
+	PartitionKey string
+	// SortKey the sort key, this is empty for operations which read more than one record
+	SortKey string
+	// Input the AWS SDK input for the request, interceptors may modify this or replace it with an input of
+	// the same type
+	Input interface{}
+}
+
+// Invoker sends the request described by the operation returning the AWS SDK output
+type Invoker func(ctx context.Context, op *Operation) (interface{}, error)
+
+// Interceptor is invoked around each request sent by a table, it can modify the operation, observe the result
+// or short-circuit the request by returning a result of the same type as the AWS SDK output without
+// calling next. A result which is missing or nil without an error fails with ErrInterceptorType.
+//
+// Interceptors run outside the retry policy, so next sends the request along with any retries.
+type Interceptor func(ctx context.Context, op *Operation, next Invoker) (interface{}, error)
+
+// chainInterceptors build an invoker which calls each interceptor in order, the first interceptor is the
+// outermost
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+
+		invoker = func(ctx context.Context, op *Operation) (interface{}, error) {
+			return interceptor(ctx, op, next)
+		}
+	}
+
+	return invoker
+}
@@ -0,0 +1,137 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func Test_sendTableInterceptors(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	cached := &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("cached")}}}
+
+	tests := []struct {
+		name         string
+		interceptors func(calls *[]string) []Interceptor
+		wantCalls    []string
+		wantSent     bool
+		wantItem     string
+		wantErr      error
+	}{
+		{
+			name: "should call interceptors in order",
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						*calls = append(*calls, "first:"+op.Name+":"+op.PartitionKey+"/"+op.SortKey)
+						return next(ctx, op)
+					},
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						*calls = append(*calls, "second")
+						return next(ctx, op)
+					},
+				}
+			},
+			wantCalls: []string{"first:Get:agent/key", "second"},
+			wantSent:  true,
+			wantItem:  "agent",
+		},
+		{
+			name: "should short-circuit the request",
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						return cached, nil
+					},
+				}
+			},
+			wantItem: "cached",
+		},
+		{
+			name: "should return error from interceptor",
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						return nil, errDenied
+					},
+				}
+			},
+			wantErr: errDenied,
+		},
+		{
+			name: "should reject a result of the wrong type",
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						return &dynamodb.QueryOutput{}, nil
+					},
+				}
+			},
+			wantErr: ErrInterceptorType,
+		},
+		{
+			name: "should reject a nil result without an error",
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						return (*dynamodb.GetItemOutput)(nil), nil
+					},
+				}
+			},
+			wantErr: ErrInterceptorType,
+		},
+		{
+			name: "should allow the input to be modified",
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+						op.Input.(*dynamodb.GetItemInput).ConsistentRead = aws.Bool(true)
+						return next(ctx, op)
+					},
+				}
+			},
+			wantSent: true,
+			wantItem: "consistent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+
+			tbl := NewWithClient(nil, defaultHooks).Table("testing", TableWithInterceptors(tt.interceptors(&calls)...))
+
+			ctx := setOperationName(context.Background(), "Get")
+
+			var sent bool
+
+			res, err := sendTable(ctx, tbl, &dynamodb.GetItemInput{Key: tbl.attributes.buildKeys("agent", "key")}, func(ctx aws.Context, in *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+				sent = true
+
+				id := aws.StringValue(in.Key["id"].S)
+				if aws.BoolValue(in.ConsistentRead) {
+					id = "consistent"
+				}
+
+				return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}}}, nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("sendTable() error = %v, want %v", err, tt.wantErr)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sendTable() sent = %v, want %v", sent, tt.wantSent)
+			}
+			if tt.wantCalls != nil && !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("sendTable() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.wantItem != "" && aws.StringValue(res.Item["id"].S) != tt.wantItem {
+				t.Errorf("sendTable() item = %v, want %s", res.Item, tt.wantItem)
+			}
+		})
+	}
+}
@@ -1,14 +1,12 @@
 package dynastore
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
-var (
-	reservedFields = map[string]string{"id": "S", "name": "S", "version": "N", "expires": "N", "payload": "A"}
-)
-
 // KVPairPage provides a page of keys with next token
 // to enable paging
 type KVPairPage struct {
@@ -29,6 +27,45 @@ type KVPair struct {
 	fields map[string]*dynamodb.AttributeValue
 }
 
+// ExpiresAt returns the time the record expires, ok is false if the record doesn't expire
+func (kv *KVPair) ExpiresAt() (expiresAt time.Time, ok bool) {
+	if kv.Expires == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(kv.Expires, 0), true
+}
+
+// TTLRemaining returns the time remaining before the record expires, this is zero if it has already expired,
+// ok is false if the record doesn't expire
+//
+// This uses the system clock, when the session is configured with another clock, such as a FakeClock, use
+// TTLRemainingAt with the time from that clock so the result agrees with the expiry of reads.
+func (kv *KVPair) TTLRemaining() (remaining time.Duration, ok bool) {
+	return kv.TTLRemainingAt(time.Now())
+}
+
+// TTLRemainingAt returns the time remaining from now before the record expires, this is zero if it has
+// already expired, ok is false if the record doesn't expire
+func (kv *KVPair) TTLRemainingAt(now time.Time) (remaining time.Duration, ok bool) {
+	expiresAt, ok := kv.ExpiresAt()
+	if !ok {
+		return 0, false
+	}
+
+	remaining = expiresAt.Sub(now)
+	if remaining < 0 {
+		return 0, true
+	}
+
+	return remaining, true
+}
+
+// isExpired the record has a TTL which has passed at the time provided
+func (kv *KVPair) isExpired(now time.Time) bool {
+	return kv.Expires != 0 && kv.Expires <= now.Unix()
+}
+
 // BytesValue use the attribute to return a slice of bytes, a nil will be returned if it is empty or nil
 func (kv *KVPair) BytesValue() []byte {
 	var buf []byte
@@ -62,3 +99,20 @@ func (kv *KVPair) DecodeValue(out interface{}) error {
 func (kv *KVPair) DecodeFields(out interface{}) error {
 	return dynamodbattribute.UnmarshalMap(kv.fields, out)
 }
+
+// ScanPage provides a page of keys read by one segment of a scan, with the
+// last key used to resume that segment, this is empty once the segment is complete
+type ScanPage struct {
+	Segment       int       `json:"segment"`
+	TotalSegments int       `json:"total_segments"`
+	Keys          []*KVPair `json:"keys"`
+	LastKey       string    `json:"last_key"`
+}
+
+// DeleteProgress reports the progress of a delete prefix operation, the LastKey can be
+// used to resume the operation, this is empty once all the keys have been deleted
+type DeleteProgress struct {
+	Deleted int    `json:"deleted"`
+	DryRun  bool   `json:"dry_run"`
+	LastKey string `json:"last_key"`
+}
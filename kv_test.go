@@ -0,0 +1,66 @@
+package dynastore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKVPair_ExpiresAt(t *testing.T) {
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name          string
+		kv            *KVPair
+		wantExpiresAt time.Time
+		wantOk        bool
+		wantRemaining bool
+	}{
+		{name: "should report no expiry", kv: &KVPair{}},
+		{name: "should report future expiry", kv: &KVPair{Expires: future.Unix()}, wantExpiresAt: future, wantOk: true, wantRemaining: true},
+		{name: "should report past expiry", kv: &KVPair{Expires: 1}, wantExpiresAt: time.Unix(1, 0), wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt, ok := tt.kv.ExpiresAt()
+			if ok != tt.wantOk || !expiresAt.Equal(tt.wantExpiresAt) {
+				t.Errorf("ExpiresAt() = %v, %v, want %v, %v", expiresAt, ok, tt.wantExpiresAt, tt.wantOk)
+			}
+
+			remaining, ok := tt.kv.TTLRemaining()
+			if ok != tt.wantOk || (remaining > 0) != tt.wantRemaining || remaining > time.Hour {
+				t.Errorf("TTLRemaining() = %v, %v, want remaining %v, %v", remaining, ok, tt.wantRemaining, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestKVPair_TTLRemainingAt(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+
+	kv := &KVPair{Expires: 1060}
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		wantRemaining time.Duration
+	}{
+		{name: "should report remaining from the clock", wantRemaining: time.Minute},
+		{name: "should report remaining after the clock advances", advance: 45 * time.Second, wantRemaining: 15 * time.Second},
+		{name: "should report zero once expired", advance: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Set(time.Unix(1000, 0).Add(tt.advance))
+
+			remaining, ok := kv.TTLRemainingAt(clock.Now())
+			if !ok || remaining != tt.wantRemaining {
+				t.Errorf("TTLRemainingAt() = %v, %v, want %v, true", remaining, ok, tt.wantRemaining)
+			}
+
+			// agrees with the expiry used by reads
+			if kv.isExpired(clock.Now()) != (remaining == 0) {
+				t.Errorf("isExpired() = %v, want %v", kv.isExpired(clock.Now()), remaining == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/wolfeidau/dynastore/logging
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go v1.45.11
+	github.com/wolfeidau/dynastore v0.0.0
+)
+
+require (
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/mr-tron/base58 v1.2.0 // indirect
+)
+
+replace github.com/wolfeidau/dynastore => ../
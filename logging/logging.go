@@ -0,0 +1,308 @@
+// Package logging provides dynastore hooks which log each request using log/slog.
+//
+// Each completed request is logged with the operation, table, shape of the key, duration and outcome. Key
+// values, payloads and fields are summarised by type and size by default so records don't leak into logs,
+// options are provided to log the values of selected attributes.
+//
+// Use dynastore.ChainHooks to combine these hooks with tracing or metrics hooks.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/wolfeidau/dynastore"
+)
+
+const redacted = "[REDACTED]"
+
+// Option assign various settings to the hooks
+type Option func(cfg *config)
+
+type config struct {
+	logger               *slog.Logger
+	level                slog.Level
+	conditionFailedLevel slog.Level
+	errorLevel           slog.Level
+	keyValues            bool
+	fieldValues          map[string]bool
+	redactedFields       map[string]bool
+}
+
+// WithLogger the logger used to log requests, this defaults to slog.Default
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// WithLevel the level used to log successful requests, this defaults to debug
+func WithLevel(level slog.Level) Option {
+	return func(cfg *config) {
+		cfg.level = level
+	}
+}
+
+// WithConditionFailedLevel the level used to log requests which failed a condition, this defaults to info
+func WithConditionFailedLevel(level slog.Level) Option {
+	return func(cfg *config) {
+		cfg.conditionFailedLevel = level
+	}
+}
+
+// WithErrorLevel the level used to log requests which returned an error, this defaults to error
+func WithErrorLevel(level slog.Level) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
+
+// WithKeyValues log the values of the partition and sort keys rather than a summary
+func WithKeyValues() Option {
+	return func(cfg *config) {
+		cfg.keyValues = true
+	}
+}
+
+// WithFieldValues log the values of the named attributes rather than a summary
+func WithFieldValues(names ...string) Option {
+	return func(cfg *config) {
+		for _, name := range names {
+			cfg.fieldValues[name] = true
+		}
+	}
+}
+
+// WithRedactedFields replace the named attributes with a placeholder, this omits the size summary
+func WithRedactedFields(names ...string) Option {
+	return func(cfg *config) {
+		for _, name := range names {
+			cfg.redactedFields[name] = true
+		}
+	}
+}
+
+// NewHooks create store hooks which log each request sent by dynastore
+func NewHooks(opts ...Option) *dynastore.StoreHooks {
+	cfg := &config{
+		logger:               slog.Default(),
+		level:                slog.LevelDebug,
+		conditionFailedLevel: slog.LevelInfo,
+		errorLevel:           slog.LevelError,
+		fieldValues:          make(map[string]bool),
+		redactedFields:       make(map[string]bool),
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &dynastore.StoreHooks{
+		RequestCompleted: cfg.requestCompleted,
+	}
+}
+
+func (cfg *config) requestCompleted(ctx context.Context, info *dynastore.RequestInfo) {
+	level, outcome := cfg.level, "ok"
+
+	switch {
+	case info.ConditionFailed:
+		level, outcome = cfg.conditionFailedLevel, "condition_failed"
+	case info.Err != nil:
+		level, outcome = cfg.errorLevel, "error"
+	}
+
+	if !cfg.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", info.Operation),
+		slog.String("table", info.TableName),
+		slog.Duration("duration", info.Duration),
+		slog.String("outcome", outcome),
+	}
+
+	if info.IndexName != "" {
+		attrs = append(attrs, slog.String("index", info.IndexName))
+	}
+
+	if info.PartitionKey != "" {
+		attrs = append(attrs, slog.String("partition", cfg.keyValue(info.PartitionKey)))
+	}
+
+	if key := inputKey(info.Input); len(key) != 0 {
+		attrs = append(attrs, slog.Any("key", cfg.summariseKey(key)))
+	}
+
+	if item := outputItem(info.Output); len(item) != 0 {
+		attrs = append(attrs, slog.Any("item", cfg.summariseItem(item)))
+	}
+
+	if info.ItemCount != 0 {
+		attrs = append(attrs, slog.Int("item_count", info.ItemCount))
+	}
+
+	if info.Retries != 0 {
+		attrs = append(attrs, slog.Int("retries", info.Retries))
+	}
+
+	if units := consumedUnits(info.ConsumedCapacity); units != 0 {
+		attrs = append(attrs, slog.Float64("consumed_capacity", units))
+	}
+
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", info.Err.Error()))
+	}
+
+	cfg.logger.LogAttrs(ctx, level, "dynastore request", attrs...)
+}
+
+func (cfg *config) keyValue(val string) string {
+	if cfg.keyValues {
+		return val
+	}
+
+	return fmt.Sprintf("S(%dB)", len(val))
+}
+
+func (cfg *config) summariseKey(key map[string]*dynamodb.AttributeValue) map[string]string {
+	summary := make(map[string]string, len(key))
+
+	for name, val := range key {
+		if cfg.keyValues && val.S != nil {
+			summary[name] = aws.StringValue(val.S)
+			continue
+		}
+
+		summary[name] = summarise(val)
+	}
+
+	return summary
+}
+
+func (cfg *config) summariseItem(item map[string]*dynamodb.AttributeValue) map[string]string {
+	summary := make(map[string]string, len(item))
+
+	for name, val := range item {
+		switch {
+		case cfg.redactedFields[name]:
+			summary[name] = redacted
+		case cfg.fieldValues[name] && val.S != nil:
+			summary[name] = aws.StringValue(val.S)
+		case cfg.fieldValues[name] && val.N != nil:
+			summary[name] = aws.StringValue(val.N)
+		default:
+			summary[name] = summarise(val)
+		}
+	}
+
+	return summary
+}
+
+// summarise describe an attribute value by its type and approximate size
+func summarise(val *dynamodb.AttributeValue) string {
+	return fmt.Sprintf("%s(%dB)", attributeType(val), attributeSize(val))
+}
+
+func attributeType(val *dynamodb.AttributeValue) string {
+	switch {
+	case val.S != nil:
+		return "S"
+	case val.N != nil:
+		return "N"
+	case val.B != nil:
+		return "B"
+	case val.BOOL != nil:
+		return "BOOL"
+	case val.NULL != nil:
+		return "NULL"
+	case val.M != nil:
+		return "M"
+	case val.L != nil:
+		return "L"
+	case val.SS != nil:
+		return "SS"
+	case val.NS != nil:
+		return "NS"
+	case val.BS != nil:
+		return "BS"
+	}
+
+	return "?"
+}
+
+func attributeSize(val *dynamodb.AttributeValue) int {
+	size := len(aws.StringValue(val.S)) + len(aws.StringValue(val.N)) + len(val.B)
+
+	if val.BOOL != nil || val.NULL != nil {
+		size++
+	}
+
+	for name, v := range val.M {
+		size += len(name) + attributeSize(v)
+	}
+
+	for _, v := range val.L {
+		size += attributeSize(v)
+	}
+
+	for _, v := range val.SS {
+		size += len(aws.StringValue(v))
+	}
+
+	for _, v := range val.NS {
+		size += len(aws.StringValue(v))
+	}
+
+	for _, v := range val.BS {
+		size += len(v)
+	}
+
+	return size
+}
+
+func inputKey(input interface{}) map[string]*dynamodb.AttributeValue {
+	switch v := input.(type) {
+	case *dynamodb.GetItemInput:
+		return v.Key
+	case *dynamodb.UpdateItemInput:
+		return v.Key
+	case *dynamodb.DeleteItemInput:
+		return v.Key
+	}
+
+	return nil
+}
+
+func outputItem(output interface{}) map[string]*dynamodb.AttributeValue {
+	switch v := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if v != nil {
+			return v.Item
+		}
+	case *dynamodb.UpdateItemOutput:
+		if v != nil {
+			return v.Attributes
+		}
+	case *dynamodb.DeleteItemOutput:
+		if v != nil {
+			return v.Attributes
+		}
+	}
+
+	return nil
+}
+
+func consumedUnits(capacity []*dynamodb.ConsumedCapacity) float64 {
+	var total float64
+
+	for _, cc := range capacity {
+		total += aws.Float64Value(cc.CapacityUnits)
+	}
+
+	return total
+}
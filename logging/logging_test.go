@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/wolfeidau/dynastore"
+)
+
+func TestNewHooks(t *testing.T) {
+	getItem := &dynastore.RequestInfo{
+		Operation:    "Get",
+		TableName:    "testing",
+		PartitionKey: "agent",
+		Duration:     10 * time.Millisecond,
+		Input: &dynamodb.GetItemInput{
+			Key: map[string]*dynamodb.AttributeValue{
+				"id":   {S: aws.String("agent")},
+				"name": {S: aws.String("customer@example.com")},
+			},
+		},
+		Output: &dynamodb.GetItemOutput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"id":      {S: aws.String("agent")},
+				"name":    {S: aws.String("customer@example.com")},
+				"payload": {S: aws.String("secret payload")},
+				"status":  {S: aws.String("enabled")},
+				"email":   {S: aws.String("customer@example.com")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		options   []Option
+		info      *dynastore.RequestInfo
+		want      map[string]interface{}
+		wantItem  map[string]string
+		wantEmpty bool
+	}{
+		{
+			name: "should summarise keys and payload by default",
+			info: getItem,
+			want: map[string]interface{}{
+				"level":     "DEBUG",
+				"operation": "Get",
+				"table":     "testing",
+				"partition": "S(5B)",
+				"outcome":   "ok",
+			},
+			wantItem: map[string]string{"payload": "S(14B)", "status": "S(7B)", "email": "S(20B)"},
+		},
+		{
+			name:    "should log selected values and redact fields",
+			options: []Option{WithKeyValues(), WithFieldValues("status"), WithRedactedFields("email")},
+			info:    getItem,
+			want: map[string]interface{}{
+				"partition": "agent",
+			},
+			wantItem: map[string]string{"payload": "S(14B)", "status": "enabled", "email": "[REDACTED]"},
+		},
+		{
+			name: "should log condition failures at info",
+			info: &dynastore.RequestInfo{Operation: "AtomicPut", TableName: "testing", Err: errors.New("failed"), ConditionFailed: true},
+			want: map[string]interface{}{
+				"level":   "INFO",
+				"outcome": "condition_failed",
+			},
+		},
+		{
+			name: "should log errors at error",
+			info: &dynastore.RequestInfo{Operation: "Get", TableName: "testing", Err: errors.New("throttled")},
+			want: map[string]interface{}{
+				"level":   "ERROR",
+				"outcome": "error",
+				"error":   "throttled",
+			},
+		},
+		{
+			name:      "should skip requests below the level of the logger",
+			options:   []Option{WithLevel(slog.LevelDebug - 1)},
+			info:      getItem,
+			wantEmpty: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			hooks := NewHooks(append([]Option{WithLogger(logger)}, tt.options...)...)
+			hooks.RequestCompleted(context.Background(), tt.info)
+
+			if tt.wantEmpty {
+				if buf.Len() != 0 {
+					t.Errorf("RequestCompleted() logged %s, want nothing", buf.String())
+				}
+				return
+			}
+
+			if strings.Contains(buf.String(), "secret payload") {
+				t.Errorf("RequestCompleted() logged payload %s", buf.String())
+			}
+
+			got := map[string]interface{}{}
+
+			err := json.Unmarshal(buf.Bytes(), &got)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("RequestCompleted() %s = %v, want %v", k, got[k], v)
+				}
+			}
+
+			item, _ := got["item"].(map[string]interface{})
+
+			for k, v := range tt.wantItem {
+				if item[k] != v {
+					t.Errorf("RequestCompleted() item.%s = %v, want %v", k, item[k], v)
+				}
+			}
+		})
+	}
+}
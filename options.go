@@ -2,10 +2,12 @@ package dynastore
 
 import (
 	"encoding/base64"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
 )
 
 const (
@@ -18,14 +20,21 @@ type SessionOption func(opts *SessionOptions)
 
 // SessionOptions contains optional request parameters
 type SessionOptions struct {
-	storeHooks *StoreHooks
+	storeHooks             *StoreHooks
+	clock                  Clock
+	retryPolicy            RetryPolicy
+	operationRetryPolicies map[string]RetryPolicy
+	pageTokens             *pageTokenSigner
 }
 
 // NewSessionOptions create session options, assign defaults then accept overrides
 func NewSessionOptions(opts ...SessionOption) *SessionOptions {
 	// assign a place holder value to detect whether to assign the default TTL
 	sessionOpts := &SessionOptions{
-		storeHooks: defaultHooks,
+		storeHooks:             defaultHooks,
+		clock:                  systemClock{},
+		retryPolicy:            DefaultRetryPolicy(),
+		operationRetryPolicies: make(map[string]RetryPolicy),
 	}
 
 	for _, opt := range opts {
@@ -42,15 +51,210 @@ func SessionWithAWSHooks(storeHooks *StoreHooks) SessionOption {
 	}
 }
 
+// SessionWithClock the clock used to compute TTLs and evaluate expiry, this defaults to the system clock.
+// Use KVPair.TTLRemainingAt with the time from this clock to compute the TTL remaining of a record.
+func SessionWithClock(clock Clock) SessionOption {
+	return func(opts *SessionOptions) {
+		opts.clock = clock
+	}
+}
+
+// SessionWithRetryPolicy the policy used to retry requests which fail with a throttling or transient error
+func SessionWithRetryPolicy(policy RetryPolicy) SessionOption {
+	return func(opts *SessionOptions) {
+		opts.retryPolicy = policy
+	}
+}
+
+// SessionWithOperationRetryPolicy the policy used to retry requests sent by the named operation, such as
+// "Scan" or "AtomicPut", this overrides the session retry policy
+func SessionWithOperationRetryPolicy(operation string, policy RetryPolicy) SessionOption {
+	return func(opts *SessionOptions) {
+		opts.operationRetryPolicies[operation] = policy
+	}
+}
+
+// SessionWithPageTokenKey sign the page tokens returned by ListPage and Scan, and the LastKey reported by
+// DeletePrefix, using an HMAC with the key provided, binding each token to the table, partition, prefix and
+// index it came from. Tokens expire after the ttl, or never if it is zero.
+//
+// The key must be at least 32 bytes, this panics if it is shorter as tokens signed with a short key can be
+// forged. Once a key is configured tokens which are unsigned, tampered with or expired fail with
+// ErrInvalidPageToken.
+func SessionWithPageTokenKey(key []byte, ttl time.Duration) SessionOption {
+	if len(key) < minPageTokenKeyLength {
+		panic(fmt.Sprintf("dynastore: page token key must be at least %d bytes, got %d", minPageTokenKeyLength, len(key)))
+	}
+
+	return func(opts *SessionOptions) {
+		opts.pageTokens = &pageTokenSigner{key: key, ttl: ttl}
+	}
+}
+
+// PartitionOption assign various settings to the partition options
+type PartitionOption func(opts *PartitionOptions)
+
+// PartitionOptions contains optional partition settings
+type PartitionOptions struct {
+	cache *Cache
+}
+
+// NewPartitionOptions create partition options, assign defaults then accept overrides
+func NewPartitionOptions(opts ...PartitionOption) *PartitionOptions {
+	partitionOpts := &PartitionOptions{}
+
+	for _, opt := range opts {
+		opt(partitionOpts)
+	}
+
+	return partitionOpts
+}
+
+// PartitionWithCache read records using Get through the cache provided, the cache is registered with the
+// session so writes and deletes made through the session invalidate it. A cache can be shared by partitions.
+//
+// Cache hits are passed through the interceptors of the table, no request is sent so the request hooks are
+// only invoked for misses.
+func PartitionWithCache(cache *Cache) PartitionOption {
+	return func(opts *PartitionOptions) {
+		opts.cache = cache
+	}
+}
+
+// TableOption assign various settings to the table options
+type TableOption func(opts *TableOptions)
+
+// TableOptions contains optional table settings
+type TableOptions struct {
+	indexes            map[string]*index
+	attributes         AttributeNames
+	readCapacityLimit  float64
+	writeCapacityLimit float64
+	indexReadLimits    map[string]float64
+	interceptors       []Interceptor
+	singleflight       bool
+}
+
+// NewTableOptions create table options, assign defaults then accept overrides
+func NewTableOptions(opts ...TableOption) *TableOptions {
+	tableOpts := &TableOptions{
+		indexes:         make(map[string]*index),
+		attributes:      DefaultAttributeNames(),
+		indexReadLimits: make(map[string]float64),
+	}
+
+	for _, opt := range opts {
+		opt(tableOpts)
+	}
+
+	return tableOpts
+}
+
+// TableWithReadCapacityLimit limit reads from the table to the given number of read capacity units per
+// second, this is shared by all the operations which read using this table including list, scan and get.
+func TableWithReadCapacityLimit(unitsPerSecond float64) TableOption {
+	return func(opts *TableOptions) {
+		opts.readCapacityLimit = unitsPerSecond
+	}
+}
+
+// TableWithWriteCapacityLimit limit writes to the table to the given number of write capacity units per
+// second, this is shared by all the operations which write using this table including batch deletes.
+func TableWithWriteCapacityLimit(unitsPerSecond float64) TableOption {
+	return func(opts *TableOptions) {
+		opts.writeCapacityLimit = unitsPerSecond
+	}
+}
+
+// TableWithIndexReadCapacityLimit limit reads from the named index to the given number of read capacity
+// units per second, reads using this index are not counted against the table read limit.
+func TableWithIndexReadCapacityLimit(name string, unitsPerSecond float64) TableOption {
+	return func(opts *TableOptions) {
+		opts.indexReadLimits[name] = unitsPerSecond
+	}
+}
+
+// TableWithInterceptors add interceptors which are invoked around each request sent by the table, these are
+// invoked in the order provided.
+func TableWithInterceptors(interceptors ...Interceptor) TableOption {
+	return func(opts *TableOptions) {
+		opts.interceptors = append(opts.interceptors, interceptors...)
+	}
+}
+
+// TableWithSingleflight collapse concurrent identical Get, Exists and ListPage calls into a single request
+// whose result is shared by each caller, consistent reads are always sent separately.
+//
+// The interceptors of the table are invoked for each caller, only the request sent to DynamoDB once they
+// have completed is shared. The request hooks are invoked once using the context of the first caller.
+//
+// Each caller still returns as soon as its own context is done, the request is cancelled once every
+// caller waiting on it has returned.
+func TableWithSingleflight() TableOption {
+	return func(opts *TableOptions) {
+		opts.singleflight = true
+	}
+}
+
+// TableWithAttributeNames use the attribute names provided to store records in the table, any names which
+// are empty are assigned the default.
+func TableWithAttributeNames(attributes AttributeNames) TableOption {
+	return func(opts *TableOptions) {
+		opts.attributes = attributes.withDefaults()
+	}
+}
+
+// TableWithLocalIndex register a local index with the given name and the name of the sort key attribute,
+// this can then be used in reads with ReadWithIndex and writes with WriteWithIndexes.
+func TableWithLocalIndex(name, sortKeyAttribute string) TableOption {
+	return func(opts *TableOptions) {
+		opts.indexes[name] = &index{
+			indexType:        indexTypeLocal,
+			name:             name,
+			sortKeyAttribute: sortKeyAttribute,
+		}
+	}
+}
+
+// TableWithGlobalIndex register a global index with the given name and the name of the partition and sort key
+// attributes, this can then be used in reads with ReadWithIndex and writes with WriteWithIndexes.
+func TableWithGlobalIndex(name, partitionKeyAttribute, sortKeyAttribute string) TableOption {
+	return func(opts *TableOptions) {
+		opts.indexes[name] = &index{
+			indexType:             indexTypeGlobal,
+			name:                  name,
+			partitionKeyAttribute: partitionKeyAttribute,
+			sortKeyAttribute:      sortKeyAttribute,
+		}
+	}
+}
+
+// TableWithSchemaIndexes register all the local and global indexes described in the table schema
+func TableWithSchemaIndexes(schema *TableSchema) TableOption {
+	return func(opts *TableOptions) {
+		for _, lsi := range schema.LocalIndexes {
+			TableWithLocalIndex(lsi.Name, lsi.SortKey.Name)(opts)
+		}
+
+		for _, gsi := range schema.GlobalIndexes {
+			TableWithGlobalIndex(gsi.Name, gsi.PartitionKey.Name, gsi.SortKey.Name)(opts)
+		}
+	}
+}
+
 // WriteOption assign various settings to the write options
 type WriteOption func(opts *WriteOptions)
 
 // WriteOptions contains optional request parameters
 type WriteOptions struct {
-	fields   map[string]*dynamodb.AttributeValue
-	value    *string
-	ttl      *time.Duration
-	previous *KVPair // Optional, previous value used to assert if the record has been modified before an atomic update
+	fields        map[string]*dynamodb.AttributeValue
+	removeFields  []string
+	removeExpires bool
+	value         *string
+	ttl           *time.Duration
+	expiresAt     *time.Time
+	previous      *KVPair // Optional, previous value used to assert if the record has been modified before an atomic update
+	indexes       []string
 }
 
 // Append append more options which supports conditional addition
@@ -76,6 +280,17 @@ func NewWriteOptions(opts ...WriteOption) *WriteOptions {
 func WriteWithTTL(ttl time.Duration) WriteOption {
 	return func(opts *WriteOptions) {
 		opts.ttl = &ttl
+		opts.expiresAt = nil
+		opts.removeExpires = false
+	}
+}
+
+// WriteWithExpiresAt the absolute time at which the key which is written expires
+func WriteWithExpiresAt(expiresAt time.Time) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.ttl = nil
+		opts.expiresAt = &expiresAt
+		opts.removeExpires = false
 	}
 }
 
@@ -83,6 +298,24 @@ func WriteWithTTL(ttl time.Duration) WriteOption {
 func WriteWithNoExpires() WriteOption {
 	return func(opts *WriteOptions) {
 		opts.ttl = nil
+		opts.expiresAt = nil
+	}
+}
+
+// WriteWithRemoveExpires remove the time to live (TTL) from an existing record so it never expires
+func WriteWithRemoveExpires() WriteOption {
+	return func(opts *WriteOptions) {
+		opts.ttl = nil
+		opts.expiresAt = nil
+		opts.removeExpires = true
+	}
+}
+
+// WriteWithoutPayload leave the payload of an existing record untouched, this clears any value assigned by
+// an earlier option so only the fields and expiry are updated
+func WriteWithoutPayload() WriteOption {
+	return func(opts *WriteOptions) {
+		opts.value = nil
 	}
 }
 
@@ -113,6 +346,14 @@ func WriteWithFields(fields map[string]string) WriteOption {
 	}
 }
 
+// WriteWithRemoveFields remove the named fields from the top level record, this is used to remove a record
+// from a sparse index
+func WriteWithRemoveFields(names ...string) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.removeFields = append(opts.removeFields, names...)
+	}
+}
+
 // WriteWithPreviousKV previous KV which will be checked prior to update
 func WriteWithPreviousKV(previous *KVPair) WriteOption {
 	return func(opts *WriteOptions) {
@@ -120,6 +361,14 @@ func WriteWithPreviousKV(previous *KVPair) WriteOption {
 	}
 }
 
+// WriteWithIndexes the record belongs to the named indexes registered with the table, the fields are checked
+// to ensure they contain all the key attributes of these indexes.
+func WriteWithIndexes(names ...string) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.indexes = append(opts.indexes, names...)
+	}
+}
+
 // ReadOption assign various settings to the read options
 type ReadOption func(opts *ReadOptions)
 
@@ -137,6 +386,8 @@ type ReadOptions struct {
 	limit            *int64
 	startKey         *string
 	index            *index
+	indexName        string
+	includeExpired   bool
 }
 
 // Append append more options which supports conditional addition
@@ -147,7 +398,7 @@ func (ro *ReadOptions) Append(opts ...ReadOption) {
 }
 
 func (ro *ReadOptions) hasIndex() bool {
-	return ro.index != nil
+	return ro.index != nil || ro.indexName != ""
 }
 
 // NewReadOptions create read options, assign defaults then accept overrides
@@ -196,10 +447,19 @@ func ReadWithLimit(limit int64) ReadOption {
 	}
 }
 
+// ReadIncludeExpired return records which have expired but haven't been removed by DynamoDB yet,
+// this is intended for admin and debug tooling which needs to see this data.
+func ReadIncludeExpired() ReadOption {
+	return func(opts *ReadOptions) {
+		opts.includeExpired = true
+	}
+}
+
 // ReadWithLocalIndex preform a read using a local index with the given name
 // and the name of the sort key attribute.
 func ReadWithLocalIndex(name, sortKeyAttribute string) ReadOption {
 	return func(opts *ReadOptions) {
+		opts.indexName = ""
 		opts.index = &index{
 			indexType:        indexTypeLocal,
 			name:             name,
@@ -208,10 +468,19 @@ func ReadWithLocalIndex(name, sortKeyAttribute string) ReadOption {
 	}
 }
 
+// ReadWithIndex preform a read using an index registered with the table using the given name.
+func ReadWithIndex(name string) ReadOption {
+	return func(opts *ReadOptions) {
+		opts.index = nil
+		opts.indexName = name
+	}
+}
+
 // ReadWithGlobalIndex preform a read using a local index with the given name
 // and the name of the partition and sort key attributes.
 func ReadWithGlobalIndex(name, partitionKeyAttribute, sortKeyAttribute string) ReadOption {
 	return func(opts *ReadOptions) {
+		opts.indexName = ""
 		opts.index = &index{
 			indexType:             indexTypeGlobal,
 			name:                  name,
@@ -220,3 +489,235 @@ func ReadWithGlobalIndex(name, partitionKeyAttribute, sortKeyAttribute string) R
 		}
 	}
 }
+
+// ScanOption assign various settings to the scan options
+type ScanOption func(opts *ScanOptions)
+
+// ScanOptions contains optional scan parameters
+type ScanOptions struct {
+	totalSegments     int
+	segments          []int
+	consistent        bool
+	limit             *int64
+	filter            *dexp.ConditionBuilder
+	projection        []string
+	startKeys         map[int]string
+	readCapacityLimit float64
+	includeExpired    bool
+}
+
+// Append append more options which supports conditional addition
+func (so *ScanOptions) Append(opts ...ScanOption) {
+	for _, opt := range opts {
+		opt(so)
+	}
+}
+
+// NewScanOptions create scan options, assign defaults then accept overrides
+// a scan defaults to a single segment with eventually consistent reads
+func NewScanOptions(opts ...ScanOption) *ScanOptions {
+	scanOpts := &ScanOptions{
+		totalSegments: 1,
+		startKeys:     make(map[int]string),
+	}
+
+	for _, opt := range opts {
+		opt(scanOpts)
+	}
+
+	return scanOpts
+}
+
+// ScanWithTotalSegments split the scan into the given number of segments, each segment is
+// read by a separate worker in parallel.
+func ScanWithTotalSegments(totalSegments int) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.totalSegments = totalSegments
+	}
+}
+
+// ScanWithSegments only scan the segments listed, this is used to skip segments which have already
+// completed when resuming a scan, or to spread the segments of one scan over a number of processes.
+func ScanWithSegments(segments ...int) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.segments = segments
+	}
+}
+
+// ScanWithStartKey resume the given segment from the exclusive start key provided, this is
+// the LastKey returned in a ScanPage for that segment.
+func ScanWithStartKey(segment int, key string) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.startKeys[segment] = key
+	}
+}
+
+// ScanConsistentEnable enable consistent reads for the scan
+func ScanConsistentEnable() ScanOption {
+	return func(opts *ScanOptions) {
+		opts.consistent = true
+	}
+}
+
+// ScanWithLimit limit the number of records evaluated by each scan request, this is the page size.
+func ScanWithLimit(limit int64) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.limit = aws.Int64(limit)
+	}
+}
+
+// ScanWithFilter filter the records returned using the condition provided, note this is applied
+// after records are read so doesn't reduce the capacity consumed by the scan.
+func ScanWithFilter(filter dexp.ConditionBuilder) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.filter = &filter
+	}
+}
+
+// ScanWithProjection only return the attributes listed, the partition and sort key attributes
+// are always returned.
+func ScanWithProjection(attributes ...string) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.projection = attributes
+	}
+}
+
+// ScanWithReadCapacityLimit limit the rate of the scan to the given number of read capacity units
+// per second, this is shared by all the segments of the scan.
+func ScanWithReadCapacityLimit(unitsPerSecond float64) ScanOption {
+	return func(opts *ScanOptions) {
+		opts.readCapacityLimit = unitsPerSecond
+	}
+}
+
+// ScanIncludeExpired return records which have expired but haven't been removed by DynamoDB yet.
+func ScanIncludeExpired() ScanOption {
+	return func(opts *ScanOptions) {
+		opts.includeExpired = true
+	}
+}
+
+// DeletePrefixOption assign various settings to the delete prefix options
+type DeletePrefixOption func(opts *DeletePrefixOptions)
+
+// DeletePrefixOptions contains optional delete prefix parameters
+type DeletePrefixOptions struct {
+	dryRun     bool
+	pageSize   *int64
+	startKey   *string
+	maxRetries int
+	progress   func(progress *DeleteProgress)
+}
+
+// Append append more options which supports conditional addition
+func (do *DeletePrefixOptions) Append(opts ...DeletePrefixOption) {
+	for _, opt := range opts {
+		opt(do)
+	}
+}
+
+// NewDeletePrefixOptions create delete prefix options, assign defaults then accept overrides
+func NewDeletePrefixOptions(opts ...DeletePrefixOption) *DeletePrefixOptions {
+	deleteOpts := &DeletePrefixOptions{
+		maxRetries: defaultBatchMaxRetries,
+	}
+
+	for _, opt := range opts {
+		opt(deleteOpts)
+	}
+
+	return deleteOpts
+}
+
+// DeleteWithDryRun list the records which would be deleted and report progress without deleting anything
+func DeleteWithDryRun() DeletePrefixOption {
+	return func(opts *DeletePrefixOptions) {
+		opts.dryRun = true
+	}
+}
+
+// DeleteWithPageSize the number of keys read by each query, these are then deleted in batches of 25.
+func DeleteWithPageSize(pageSize int64) DeletePrefixOption {
+	return func(opts *DeletePrefixOptions) {
+		opts.pageSize = aws.Int64(pageSize)
+	}
+}
+
+// DeleteWithStartKey resume an interrupted delete using the LastKey from the last progress reported
+func DeleteWithStartKey(key string) DeletePrefixOption {
+	return func(opts *DeletePrefixOptions) {
+		opts.startKey = aws.String(key)
+	}
+}
+
+// DeleteWithMaxRetries the number of times unprocessed items in a batch are retried before giving up
+func DeleteWithMaxRetries(maxRetries int) DeletePrefixOption {
+	return func(opts *DeletePrefixOptions) {
+		opts.maxRetries = maxRetries
+	}
+}
+
+// DeleteWithProgress invoke the function provided after each page of keys has been deleted
+func DeleteWithProgress(progress func(progress *DeleteProgress)) DeletePrefixOption {
+	return func(opts *DeletePrefixOptions) {
+		opts.progress = progress
+	}
+}
+
+// UpdateOption assign various settings to the update options
+type UpdateOption func(opts *UpdateOptions)
+
+// UpdateOptions contains optional update parameters
+type UpdateOptions struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	create      bool
+}
+
+// Append append more options which supports conditional addition
+func (uo *UpdateOptions) Append(opts ...UpdateOption) {
+	for _, opt := range opts {
+		opt(uo)
+	}
+}
+
+// NewUpdateOptions create update options, assign defaults then accept overrides
+// records are created if missing by default
+func NewUpdateOptions(opts ...UpdateOption) *UpdateOptions {
+	updateOpts := &UpdateOptions{
+		maxAttempts: defaultUpdateMaxAttempts,
+		baseDelay:   defaultUpdateBaseDelay,
+		maxDelay:    defaultUpdateMaxDelay,
+		create:      true,
+	}
+
+	for _, opt := range opts {
+		opt(updateOpts)
+	}
+
+	return updateOpts
+}
+
+// UpdateWithMaxAttempts the number of times the update is attempted before giving up on a conflict
+func UpdateWithMaxAttempts(maxAttempts int) UpdateOption {
+	return func(opts *UpdateOptions) {
+		opts.maxAttempts = maxAttempts
+	}
+}
+
+// UpdateWithBackoff the base and maximum delay between attempts, the actual delay is randomised
+// between zero and the exponentially increasing delay.
+func UpdateWithBackoff(baseDelay, maxDelay time.Duration) UpdateOption {
+	return func(opts *UpdateOptions) {
+		opts.baseDelay = baseDelay
+		opts.maxDelay = maxDelay
+	}
+}
+
+// UpdateWithoutCreate return ErrKeyNotFound rather than creating the record if it is missing
+func UpdateWithoutCreate() UpdateOption {
+	return func(opts *UpdateOptions) {
+		opts.create = false
+	}
+}
@@ -0,0 +1,228 @@
+// Package otel provides dynastore hooks which trace each request using OpenTelemetry and record
+// request latency and error metrics.
+//
+// A span is started for each dynastore operation, named after the operation such as "dynastore.Get", with a
+// child span for each request the operation sends to DynamoDB, named after the DynamoDB API such as
+// "DynamoDB.GetItem". Operations such as List, Scan and Update, along with any retries, produce a child
+// span per request under the one operation span.
+//
+// Use dynastore.ChainHooks to combine these hooks with metrics or logging hooks.
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wolfeidau/dynastore"
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/wolfeidau/dynastore/otel"
+
+// Attribute keys added to spans and metrics
+const (
+	DBSystemKey         = attribute.Key("db.system")
+	TableNameKey        = attribute.Key("aws.dynamodb.table_names")
+	IndexNameKey        = attribute.Key("aws.dynamodb.index_name")
+	ConsumedCapacityKey = attribute.Key("aws.dynamodb.consumed_capacity")
+	OperationKey        = attribute.Key("dynastore.operation")
+	PartitionKey        = attribute.Key("dynastore.partition")
+	ItemCountKey        = attribute.Key("dynastore.item_count")
+	RetriesKey          = attribute.Key("dynastore.retries")
+	ConditionFailedKey  = attribute.Key("dynastore.condition_failed")
+)
+
+// Option assign various settings to the hooks
+type Option func(cfg *config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider the tracer provider used to create spans, this defaults to the global provider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider the meter provider used to record metrics, this defaults to the global provider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(cfg *config) {
+		cfg.meterProvider = mp
+	}
+}
+
+type hooks struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// NewHooks create store hooks which trace and record metrics for each request sent by dynastore
+func NewHooks(opts ...Option) (*dynastore.StoreHooks, error) {
+	cfg := &config{
+		tracerProvider: otelapi.GetTracerProvider(),
+		meterProvider:  otelapi.GetMeterProvider(),
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram("dynastore.request.duration",
+		metric.WithDescription("The duration of requests sent to DynamoDB"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duration histogram: %w", err)
+	}
+
+	requestErrors, err := meter.Int64Counter("dynastore.request.errors",
+		metric.WithDescription("The number of requests sent to DynamoDB which returned an error"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create errors counter: %w", err)
+	}
+
+	h := &hooks{
+		tracer:   cfg.tracerProvider.Tracer(instrumentationName),
+		duration: duration,
+		errors:   requestErrors,
+	}
+
+	return &dynastore.StoreHooks{
+		OperationStarted:   h.operationStarted,
+		OperationCompleted: h.operationCompleted,
+		RequestBuilt:       h.requestBuilt,
+		RequestCompleted:   h.requestCompleted,
+	}, nil
+}
+
+func (h *hooks) operationStarted(ctx context.Context, info *dynastore.OperationInfo) context.Context {
+	attrs := []attribute.KeyValue{
+		DBSystemKey.String("dynamodb"),
+		OperationKey.String(info.Operation),
+		TableNameKey.StringSlice([]string{info.TableName}),
+	}
+
+	if info.PartitionKey != "" {
+		attrs = append(attrs, PartitionKey.String(info.PartitionKey))
+	}
+
+	ctx, _ = h.tracer.Start(ctx, "dynastore."+info.Operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx
+}
+
+func (h *hooks) operationCompleted(ctx context.Context, info *dynastore.OperationInfo) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	// missing records and failed conditions are expected outcomes of an operation so aren't flagged as errors
+	if info.Err == nil || isExpectedOutcome(info.Err) {
+		return
+	}
+
+	span.RecordError(info.Err)
+	span.SetStatus(codes.Error, info.Err.Error())
+}
+
+func (h *hooks) requestBuilt(ctx context.Context, params interface{}) context.Context {
+	attrs := []attribute.KeyValue{
+		DBSystemKey.String("dynamodb"),
+		OperationKey.String(dynastore.OperationName(ctx)),
+	}
+
+	if partition := dynastore.PartitionKey(ctx); partition != "" {
+		attrs = append(attrs, PartitionKey.String(partition))
+	}
+
+	// a child of the operation span when one has been started
+	ctx, _ = h.tracer.Start(ctx, "DynamoDB."+apiName(params),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx
+}
+
+func (h *hooks) requestCompleted(ctx context.Context, info *dynastore.RequestInfo) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	span.SetAttributes(
+		TableNameKey.StringSlice([]string{info.TableName}),
+		ItemCountKey.Int(info.ItemCount),
+		RetriesKey.Int(info.Retries),
+		ConditionFailedKey.Bool(info.ConditionFailed),
+		ConsumedCapacityKey.Float64(totalCapacity(info)),
+	)
+
+	if info.IndexName != "" {
+		span.SetAttributes(IndexNameKey.String(info.IndexName))
+	}
+
+	attrs := []attribute.KeyValue{
+		TableNameKey.String(info.TableName),
+		OperationKey.String(info.Operation),
+	}
+
+	h.duration.Record(ctx, info.Duration.Seconds(), metric.WithAttributes(attrs...))
+
+	if info.Err == nil {
+		return
+	}
+
+	h.errors.Add(ctx, 1, metric.WithAttributes(append(attrs, ConditionFailedKey.Bool(info.ConditionFailed))...))
+
+	// a failed condition is an expected outcome of a conditional write so isn't flagged as an error
+	if info.ConditionFailed {
+		return
+	}
+
+	span.RecordError(info.Err)
+	span.SetStatus(codes.Error, info.Err.Error())
+}
+
+// apiName the name of the DynamoDB API for the AWS SDK input, such as GetItem for a *dynamodb.GetItemInput
+func apiName(params interface{}) string {
+	name := fmt.Sprintf("%T", params)
+
+	if n := strings.LastIndex(name, "."); n >= 0 {
+		name = name[n+1:]
+	}
+
+	return strings.TrimSuffix(name, "Input")
+}
+
+func isExpectedOutcome(err error) bool {
+	return errors.Is(err, dynastore.ErrKeyNotFound) ||
+		errors.Is(err, dynastore.ErrKeyExists) ||
+		errors.Is(err, dynastore.ErrKeyModified) ||
+		errors.Is(err, dynastore.ErrKeyExpired)
+}
+
+func totalCapacity(info *dynastore.RequestInfo) float64 {
+	var total float64
+
+	for _, cc := range info.ConsumedCapacity {
+		if cc.CapacityUnits != nil {
+			total += *cc.CapacityUnits
+		}
+	}
+
+	return total
+}
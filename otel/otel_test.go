@@ -0,0 +1,201 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/wolfeidau/dynastore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewHooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		info       *dynastore.RequestInfo
+		wantStatus codes.Code
+		wantErrors int64
+	}{
+		{
+			name: "should trace successful request",
+			info: &dynastore.RequestInfo{
+				Operation:        "ListPage",
+				TableName:        "testing",
+				IndexName:        "idx_created",
+				Duration:         15 * time.Millisecond,
+				ItemCount:        3,
+				ConsumedCapacity: []*dynamodb.ConsumedCapacity{{CapacityUnits: aws.Float64(0.5)}},
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name: "should not flag condition failure as an error",
+			info: &dynastore.RequestInfo{
+				Operation:       "AtomicPut",
+				TableName:       "testing",
+				Err:             errors.New("ConditionalCheckFailedException"),
+				ConditionFailed: true,
+			},
+			wantStatus: codes.Unset,
+			wantErrors: 1,
+		},
+		{
+			name: "should flag failed request as an error",
+			info: &dynastore.RequestInfo{
+				Operation: "Get",
+				TableName: "testing",
+				Err:       errors.New("ProvisionedThroughputExceededException"),
+			},
+			wantStatus: codes.Error,
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			reader := sdkmetric.NewManualReader()
+
+			hooks, err := NewHooks(
+				WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+				WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+			)
+			if err != nil {
+				t.Fatalf("NewHooks() error = %v", err)
+			}
+
+			ctx := context.WithValue(context.Background(), dynastore.OperationNameKey, tt.info.Operation)
+			ctx = context.WithValue(ctx, dynastore.PartitionKeyKey, "agent")
+
+			ctx = hooks.RequestBuilt(ctx, &dynamodb.GetItemInput{})
+			hooks.RequestCompleted(ctx, tt.info)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("spans = %d, want 1", len(spans))
+			}
+
+			span := spans[0]
+
+			if want := "DynamoDB.GetItem"; span.Name != want {
+				t.Errorf("span name = %s, want %s", span.Name, want)
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+
+			attrs := attribute.NewSet(span.Attributes...)
+
+			if v, _ := attrs.Value(PartitionKey); v.AsString() != "agent" {
+				t.Errorf("span partition = %q, want agent", v.AsString())
+			}
+			if v, _ := attrs.Value(ItemCountKey); v.AsInt64() != int64(tt.info.ItemCount) {
+				t.Errorf("span item count = %d, want %d", v.AsInt64(), tt.info.ItemCount)
+			}
+			if v, _ := attrs.Value(ConditionFailedKey); v.AsBool() != tt.info.ConditionFailed {
+				t.Errorf("span condition failed = %v, want %v", v.AsBool(), tt.info.ConditionFailed)
+			}
+
+			var rm metricdata.ResourceMetrics
+
+			err = reader.Collect(context.Background(), &rm)
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			var gotErrors int64
+			var gotDurations uint64
+
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					switch data := m.Data.(type) {
+					case metricdata.Sum[int64]:
+						for _, dp := range data.DataPoints {
+							gotErrors += dp.Value
+						}
+					case metricdata.Histogram[float64]:
+						for _, dp := range data.DataPoints {
+							gotDurations += dp.Count
+						}
+					}
+				}
+			}
+
+			if gotDurations != 1 {
+				t.Errorf("duration count = %d, want 1", gotDurations)
+			}
+			if gotErrors != tt.wantErrors {
+				t.Errorf("errors = %d, want %d", gotErrors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestNewHooks_operationSpan(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "should trace successful operation", wantStatus: codes.Unset},
+		{name: "should not flag missing record as an error", err: fmt.Errorf("failed: %w", dynastore.ErrKeyNotFound), wantStatus: codes.Unset},
+		{name: "should flag failed operation as an error", err: errors.New("failed to list"), wantStatus: codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+
+			hooks, err := NewHooks(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))))
+			if err != nil {
+				t.Fatalf("NewHooks() error = %v", err)
+			}
+
+			info := &dynastore.OperationInfo{Operation: "List", TableName: "testing", PartitionKey: "agent"}
+
+			ctx := hooks.OperationStarted(context.Background(), info)
+
+			// each page of the list is a separate request
+			for i := 0; i < 2; i++ {
+				reqCtx := hooks.RequestBuilt(ctx, &dynamodb.QueryInput{})
+				hooks.RequestCompleted(reqCtx, &dynastore.RequestInfo{Operation: "List", TableName: "testing"})
+			}
+
+			info.Err = tt.err
+			hooks.OperationCompleted(ctx, info)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 3 {
+				t.Fatalf("spans = %d, want 3", len(spans))
+			}
+
+			operation := spans[2]
+
+			if operation.Name != "dynastore.List" {
+				t.Errorf("operation span name = %s, want dynastore.List", operation.Name)
+			}
+			if operation.Parent.IsValid() {
+				t.Errorf("operation span has parent %s, want none", operation.Parent.SpanID())
+			}
+			if operation.Status.Code != tt.wantStatus {
+				t.Errorf("operation span status = %v, want %v", operation.Status.Code, tt.wantStatus)
+			}
+
+			for _, span := range spans[:2] {
+				if span.Name != "DynamoDB.Query" {
+					t.Errorf("request span name = %s, want DynamoDB.Query", span.Name)
+				}
+				if span.Parent.SpanID() != operation.SpanContext.SpanID() {
+					t.Errorf("request span parent = %s, want %s", span.Parent.SpanID(), operation.SpanContext.SpanID())
+				}
+			}
+		})
+	}
+}
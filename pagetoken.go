@@ -0,0 +1,134 @@
+package dynastore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	pageTokenSeparator = "."
+
+	// minPageTokenKeyLength the shortest key accepted for signing page tokens, this matches the size of the
+	// SHA-256 HMAC
+	minPageTokenKeyLength = 32
+)
+
+// pageTokenSigner signs page tokens with an HMAC so they can be handed to clients
+//
+// The signature covers the scope of the read the token came from, such as the table, partition, prefix and
+// index, so a token is only accepted when resuming the same read. The scope isn't stored in the token.
+type pageTokenSigner struct {
+	key []byte
+	ttl time.Duration
+}
+
+// sign encode the key and sign it along with the scope, the token expires after the ttl of the signer
+func (ps *pageTokenSigner) sign(scope []string, key map[string]*dynamodb.AttributeValue, now time.Time) (string, error) {
+	encoded, err := encodeKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	var expires int64
+
+	if ps.ttl > 0 {
+		expires = now.Add(ps.ttl).Unix()
+	}
+
+	payload := encoded + pageTokenSeparator + strconv.FormatInt(expires, base10)
+
+	return payload + pageTokenSeparator + ps.signature(scope, payload), nil
+}
+
+// verify check the signature and expiry of the token then decode the key
+func (ps *pageTokenSigner) verify(scope []string, token string, now time.Time) (map[string]*dynamodb.AttributeValue, error) {
+	n := strings.LastIndex(token, pageTokenSeparator)
+	if n < 0 {
+		return nil, fmt.Errorf("token isn't signed: %w", ErrInvalidPageToken)
+	}
+
+	payload, signature := token[:n], token[n+1:]
+
+	if !hmac.Equal([]byte(signature), []byte(ps.signature(scope, payload))) {
+		return nil, fmt.Errorf("signature doesn't match: %w", ErrInvalidPageToken)
+	}
+
+	n = strings.LastIndex(payload, pageTokenSeparator)
+	if n < 0 {
+		return nil, fmt.Errorf("token is missing expiry: %w", ErrInvalidPageToken)
+	}
+
+	expires, err := strconv.ParseInt(payload[n+1:], base10, int64bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expiry: %w", ErrInvalidPageToken)
+	}
+
+	if expires != 0 && expires <= now.Unix() {
+		return nil, fmt.Errorf("token expired at %s: %w", time.Unix(expires, 0).UTC().Format(time.RFC3339), ErrInvalidPageToken)
+	}
+
+	key, err := decodeKey(payload[:n])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", ErrInvalidPageToken)
+	}
+
+	return key, nil
+}
+
+func (ps *pageTokenSigner) signature(scope []string, payload string) string {
+	mac := hmac.New(sha256.New, ps.key)
+
+	// each part is terminated so the boundaries between them are signed
+	for _, part := range append(scope, payload) {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encodePageToken encode the last evaluated key of a read, signing it when the session has a page token key
+func (dt *DynaTable) encodePageToken(scope []string, key map[string]*dynamodb.AttributeValue) (string, error) {
+	if dt.session.pageTokens == nil {
+		return encodeKey(key)
+	}
+
+	return dt.session.pageTokens.sign(append([]string{dt.GetTableName()}, scope...), key, dt.now())
+}
+
+// decodePageToken decode a token returned by encodePageToken for a read with the same scope
+func (dt *DynaTable) decodePageToken(scope []string, token string) (map[string]*dynamodb.AttributeValue, error) {
+	if dt.session.pageTokens == nil {
+		return decodeKey(token)
+	}
+
+	return dt.session.pageTokens.verify(append([]string{dt.GetTableName()}, scope...), token, dt.now())
+}
+
+// listPageScope the scope of a page token returned by ListPage
+func listPageScope(partitionKey, prefix string, readOptions *ReadOptions) []string {
+	var indexName string
+
+	if readOptions.index != nil {
+		indexName = readOptions.index.name
+	}
+
+	return []string{"ListPage", partitionKey, prefix, indexName}
+}
+
+// deletePrefixScope the scope of a page token returned in the progress of DeletePrefix
+func deletePrefixScope(partitionKey, prefix string) []string {
+	return []string{"DeletePrefix", partitionKey, prefix}
+}
+
+// scanScope the scope of a page token returned for a segment of a Scan
+func scanScope(segment, totalSegments int) []string {
+	return []string{"Scan", strconv.Itoa(segment), strconv.Itoa(totalSegments)}
+}
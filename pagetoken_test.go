@@ -0,0 +1,163 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func Test_pageTokenSigner(t *testing.T) {
+	now := time.Now()
+
+	key := map[string]*dynamodb.AttributeValue{
+		"id":   {S: aws.String("agent")},
+		"name": {S: aws.String("key")},
+	}
+
+	signer := &pageTokenSigner{key: []byte("secret"), ttl: time.Hour}
+	scope := listPageScope("agent", "prefix", &ReadOptions{})
+
+	token, err := signer.sign(scope, key, now)
+	if err != nil {
+		t.Fatalf("sign() error = %v", err)
+	}
+
+	legacy, err := compressAndEncodeKey(key)
+	if err != nil {
+		t.Fatalf("compressAndEncodeKey() error = %v", err)
+	}
+
+	other, err := compressAndEncodeKey(map[string]*dynamodb.AttributeValue{
+		"id":   {S: aws.String("other")},
+		"name": {S: aws.String("key")},
+	})
+	if err != nil {
+		t.Fatalf("compressAndEncodeKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		signer  *pageTokenSigner
+		scope   []string
+		token   string
+		now     time.Time
+		wantErr bool
+	}{
+		{name: "should accept token for the same read", signer: signer, scope: scope, token: token, now: now},
+		{name: "should reject token for another partition", signer: signer, scope: listPageScope("other", "prefix", &ReadOptions{}), token: token, now: now, wantErr: true},
+		{name: "should reject token for another prefix", signer: signer, scope: listPageScope("agent", "pre", &ReadOptions{}), token: token, now: now, wantErr: true},
+		{name: "should reject token for another index", signer: signer, scope: listPageScope("agent", "prefix", &ReadOptions{index: &index{name: "idx_created"}}), token: token, now: now, wantErr: true},
+		{name: "should reject token signed with another key", signer: &pageTokenSigner{key: []byte("other"), ttl: time.Hour}, scope: scope, token: token, now: now, wantErr: true},
+		{name: "should reject token which has been modified", signer: signer, scope: scope, token: other + token[strings.Index(token, pageTokenSeparator):], now: now, wantErr: true},
+		{name: "should reject token which has expired", signer: signer, scope: scope, token: token, now: now.Add(2 * time.Hour), wantErr: true},
+		{name: "should reject token which isn't signed", signer: signer, scope: scope, token: legacy, now: now, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.signer.verify(tt.scope, tt.token, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPageToken) {
+					t.Errorf("verify() error = %v, want ErrInvalidPageToken", err)
+				}
+				return
+			}
+
+			if aws.StringValue(got["name"].S) != "key" {
+				t.Errorf("verify() = %v, want key", got)
+			}
+		})
+	}
+}
+
+func Test_pageTokenSigner_withoutExpiry(t *testing.T) {
+	now := time.Now()
+
+	signer := &pageTokenSigner{key: []byte("secret")}
+	scope := scanScope(0, 1)
+
+	token, err := signer.sign(scope, map[string]*dynamodb.AttributeValue{"id": {S: aws.String("agent")}}, now)
+	if err != nil {
+		t.Fatalf("sign() error = %v", err)
+	}
+
+	if _, err := signer.verify(scope, token, now.Add(24*365*time.Hour)); err != nil {
+		t.Errorf("verify() error = %v", err)
+	}
+
+	if _, err := signer.verify(scanScope(1, 2), token, now); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("verify() error = %v, want ErrInvalidPageToken", err)
+	}
+}
+
+func TestSessionWithPageTokenKey_shortKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("SessionWithPageTokenKey() didn't panic with a short key")
+		}
+	}()
+
+	SessionWithPageTokenKey([]byte("secret"), time.Hour)
+}
+
+func TestDynaTable_DeletePrefixPageToken(t *testing.T) {
+	var queries int
+
+	// the first query returns a last key so the delete reports a token
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		queries++
+		if queries == 1 {
+			return map[string]interface{}{
+				"Items":            []interface{}{},
+				"LastEvaluatedKey": map[string]interface{}{"id": map[string]string{"S": "agent"}, "name": map[string]string{"S": "key1"}},
+			}
+		}
+
+		return map[string]interface{}{"Items": []interface{}{}}
+	})
+
+	tbl := NewWithClient(svc, defaultHooks, SessionWithPageTokenKey([]byte(strings.Repeat("k", minPageTokenKeyLength)), time.Hour)).Table("testing")
+
+	var token string
+
+	_, err := tbl.DeletePrefixWithContext(context.Background(), "agent", "key", DeleteWithDryRun(), DeleteWithProgress(func(progress *DeleteProgress) {
+		if token == "" {
+			token = progress.LastKey
+		}
+	}))
+	if err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+
+	unsigned, err := encodeKey(map[string]*dynamodb.AttributeValue{"id": {S: aws.String("agent")}, "name": {S: aws.String("key1")}})
+	if err != nil {
+		t.Fatalf("encodeKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		token   string
+		wantErr bool
+	}{
+		{name: "should resume the same delete", prefix: "key", token: token},
+		{name: "should reject token for another prefix", prefix: "other", token: token, wantErr: true},
+		{name: "should reject token which isn't signed", prefix: "key", token: unsigned, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tbl.DeletePrefixWithContext(context.Background(), "agent", tt.prefix, DeleteWithDryRun(), DeleteWithStartKey(tt.token))
+			if tt.wantErr != errors.Is(err, ErrInvalidPageToken) || (!tt.wantErr && err != nil) {
+				t.Errorf("DeletePrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
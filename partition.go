@@ -3,6 +3,8 @@ package dynastore
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -13,6 +15,7 @@ type DynaPartition struct {
 	session   *DynaSession
 	table     *DynaTable
 	partition string
+	cache     *Cache
 }
 
 func (ddb *DynaPartition) GetTableName() string {
@@ -33,6 +36,16 @@ func (ddb *DynaPartition) PutWithContext(ctx context.Context, hashKey string, op
 	return ddb.table.PutWithContext(ctx, ddb.partition, hashKey, options...)
 }
 
+// PutKV put a value at the specified key returning the resulting record
+func (ddb *DynaPartition) PutKV(hashKey string, options ...WriteOption) (*KVPair, error) {
+	return ddb.PutKVWithContext(context.Background(), hashKey, options...)
+}
+
+// PutKVWithContext put a value at the specified key returning the resulting record
+func (ddb *DynaPartition) PutKVWithContext(ctx context.Context, hashKey string, options ...WriteOption) (*KVPair, error) {
+	return ddb.table.PutKVWithContext(ctx, ddb.partition, hashKey, options...)
+}
+
 // Exists if a sort key exists in the store
 func (ddb *DynaPartition) Exists(sortKey string, options ...ReadOption) (bool, error) {
 	return ddb.ExistsWithContext(context.Background(), sortKey, options...)
@@ -49,8 +62,49 @@ func (ddb *DynaPartition) Get(sortKey string, options ...ReadOption) (*KVPair, e
 }
 
 // Get a value given its key
+//
+// If the partition has a cache the record is read from it, unless the read is consistent. Records read from
+// the cache are passed through the interceptors of the table. When a record read to fill the cache is older
+// than a version already observed it is read again using a consistent read, so an older version is never
+// returned.
 func (ddb *DynaPartition) GetWithContext(ctx context.Context, sortKey string, options ...ReadOption) (*KVPair, error) {
-	return ddb.table.GetWithContext(ctx, ddb.partition, sortKey, options...)
+	if ddb.cache == nil {
+		return ddb.table.GetWithContext(ctx, ddb.partition, sortKey, options...)
+	}
+
+	readOptions := NewReadOptions(options...)
+
+	if readOptions.consistent || readOptions.includeExpired || readOptions.hasIndex() {
+		return ddb.table.GetWithContext(ctx, ddb.partition, sortKey, options...)
+	}
+
+	key := cacheKey{table: ddb.GetTableName(), partition: ddb.partition, key: sortKey}
+
+	// the cached record is still passed through the interceptors of the table
+	if kv, ok := ddb.cache.get(key, ddb.table.now()); ok {
+		return ddb.table.GetWithContext(withCachedRecord(ctx, kv), ddb.partition, sortKey, options...)
+	}
+
+	kv, err := ddb.table.GetWithContext(ctx, ddb.partition, sortKey, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *kv
+	if ddb.cache.set(key, &cached, ddb.table.now()) {
+		return kv, nil
+	}
+
+	// the read returned a version older than one already observed, so read the latest version consistently
+	kv, err = ddb.table.GetWithContext(ctx, ddb.partition, sortKey, append(options[:len(options):len(options)], func(opts *ReadOptions) { opts.consistent = true })...)
+	if err != nil {
+		return nil, err
+	}
+
+	cached = *kv
+	ddb.cache.replace(key, &cached, ddb.table.now())
+
+	return kv, nil
 }
 
 // Delete the value at the specified key
@@ -63,6 +117,16 @@ func (ddb *DynaPartition) DeleteWithContext(ctx context.Context, sortKey string)
 	return ddb.table.DeleteWithContext(ctx, ddb.partition, sortKey)
 }
 
+// DeleteKV delete the value at the specified key returning whether it existed and the record removed
+func (ddb *DynaPartition) DeleteKV(sortKey string) (bool, *KVPair, error) {
+	return ddb.DeleteKVWithContext(context.Background(), sortKey)
+}
+
+// DeleteKVWithContext delete the value at the specified key returning whether it existed and the record removed
+func (ddb *DynaPartition) DeleteKVWithContext(ctx context.Context, sortKey string) (bool, *KVPair, error) {
+	return ddb.table.DeleteKVWithContext(ctx, ddb.partition, sortKey)
+}
+
 // List the content of a given prefix
 func (ddb *DynaPartition) ListPage(prefix string, options ...ReadOption) (*KVPairPage, error) {
 	return ddb.ListPageWithContext(context.Background(), prefix, options...)
@@ -85,44 +149,53 @@ func (ddb *DynaPartition) List(prefix string, options ...ReadOption) ([]*KVPair,
 //
 // Deprecated: This function attempts to list all records using a deadline / timeout which turned out to be
 // a bad idea, use ListPageWithContext
-func (ddb *DynaPartition) ListWithContext(ctx context.Context, prefix string, options ...ReadOption) ([]*KVPair, error) {
+func (ddb *DynaPartition) ListWithContext(ctx context.Context, prefix string, options ...ReadOption) (_ []*KVPair, err error) {
 	readOptions := NewReadOptions(options...)
 
-	ctx = setOperationName(ctx, "List")
+	ctx, done := ddb.session.startOperation(ctx, "List", ddb.GetTableName(), ddb.partition)
+	defer func() { done(err) }()
 
 	query := &dynamodb.QueryInput{
 		TableName:              aws.String(ddb.GetTableName()),
 		KeyConditionExpression: aws.String("#id = :partition AND begins_with(#name, :namePrefix)"),
 		ExpressionAttributeNames: map[string]*string{
-			"#id":   aws.String("id"),
-			"#name": aws.String("name"),
+			"#id":   aws.String(ddb.table.attributes.PartitionKey),
+			"#name": aws.String(ddb.table.attributes.SortKey),
 		},
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":partition":  {S: aws.String(ddb.partition)},
 			":namePrefix": {S: aws.String(prefix)},
 		},
-		ConsistentRead: aws.Bool(readOptions.consistent),
+		ConsistentRead:         aws.Bool(readOptions.consistent),
+		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+	}
+
+	// expired records are filtered by DynamoDB rather than after they are read
+	if !readOptions.includeExpired {
+		query.FilterExpression = aws.String("attribute_not_exists(#expires) OR #expires > :timeNow")
+		query.ExpressionAttributeNames["#expires"] = aws.String(ddb.table.attributes.Expires)
+		query.ExpressionAttributeValues[":timeNow"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(ddb.table.now().Unix(), base10))}
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, listDefaultTimeout)
+	defer cancel()
 
 	var items []map[string]*dynamodb.AttributeValue
 
-	ctx = ddb.session.storeHooks.RequestBuilt(ctx, query)
+	// each page is sent separately so it is passed to the hooks
+	for {
+		res, err := sendTable(ctx, ddb.table, query, ddb.session.QueryWithContext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query table: %w", err)
+		}
 
-	err := ddb.session.QueryPagesWithContext(ctx, query,
-		func(page *dynamodb.QueryOutput, lastPage bool) bool {
-			items = append(items, page.Items...)
+		items = append(items, res.Items...)
 
-			if lastPage {
-				cancel()
-				return false
-			}
+		if len(res.LastEvaluatedKey) == 0 {
+			break
+		}
 
-			return true
-		})
-	if err != nil {
-		return nil, fmt.Errorf("failed to query table: %w", err)
+		query.ExclusiveStartKey = res.LastEvaluatedKey
 	}
 
 	if len(items) == 0 {
@@ -132,16 +205,11 @@ func (ddb *DynaPartition) ListWithContext(ctx context.Context, prefix string, op
 	var results []*KVPair
 
 	for _, item := range items {
-		val, err := DecodeItem(item)
+		val, err := ddb.table.attributes.decodeItem(item)
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode item: %w", err)
 		}
 
-		// skip records which are expired
-		if isItemExpired(item) {
-			continue
-		}
-
 		results = append(results, val)
 	}
 
@@ -162,8 +230,9 @@ func (ddb *DynaPartition) AtomicPutWithContext(ctx context.Context, sortKey stri
 //
 // This supports two different operations:
 // * if previous is supplied assert it exists with the version supplied
-// * if previous is nil then assert that the key doesn't exist
+// * if previous is nil then assert it exists with any version
 //
+// See DynaTable.AtomicDeleteWithContext for the errors returned for each outcome.
 func (ddb *DynaPartition) AtomicDelete(sortKey string, previous *KVPair) (bool, error) {
 	return ddb.AtomicDeleteWithContext(context.Background(), sortKey, previous)
 }
@@ -172,9 +241,39 @@ func (ddb *DynaPartition) AtomicDelete(sortKey string, previous *KVPair) (bool,
 //
 // This supports two different operations:
 // * if previous is supplied assert it exists with the version supplied
-// * if previous is nil then assert that the key doesn't exist
+// * if previous is nil then assert it exists with any version
 //
-// FIXME: should the second case just return false, nil?
+// See DynaTable.AtomicDeleteWithContext for the errors returned for each outcome.
 func (ddb *DynaPartition) AtomicDeleteWithContext(ctx context.Context, sortKey string, previous *KVPair) (bool, error) {
 	return ddb.table.AtomicDeleteWithContext(ctx, ddb.partition, sortKey, previous)
 }
+
+// DeletePrefix delete all the records with a sort key starting with the given prefix
+func (ddb *DynaPartition) DeletePrefix(prefix string, options ...DeletePrefixOption) (*DeleteProgress, error) {
+	return ddb.DeletePrefixWithContext(context.Background(), prefix, options...)
+}
+
+// DeletePrefixWithContext delete all the records with a sort key starting with the given prefix
+func (ddb *DynaPartition) DeletePrefixWithContext(ctx context.Context, prefix string, options ...DeletePrefixOption) (*DeleteProgress, error) {
+	return ddb.table.DeletePrefixWithContext(ctx, ddb.partition, prefix, options...)
+}
+
+// Update perform an optimistic read, modify and write of a single value, retrying if it is modified concurrently
+func (ddb *DynaPartition) Update(sortKey string, fn UpdateFunc, options ...UpdateOption) (*KVPair, error) {
+	return ddb.UpdateWithContext(context.Background(), sortKey, fn, options...)
+}
+
+// UpdateWithContext perform an optimistic read, modify and write of a single value, retrying if it is modified concurrently
+func (ddb *DynaPartition) UpdateWithContext(ctx context.Context, sortKey string, fn UpdateFunc, options ...UpdateOption) (*KVPair, error) {
+	return ddb.table.UpdateWithContext(ctx, ddb.partition, sortKey, fn, options...)
+}
+
+// Touch update the time to live (TTL) of an existing value without rewriting its payload
+func (ddb *DynaPartition) Touch(sortKey string, ttl time.Duration, previous *KVPair) (*KVPair, error) {
+	return ddb.TouchWithContext(context.Background(), sortKey, ttl, previous)
+}
+
+// TouchWithContext update the time to live (TTL) of an existing value without rewriting its payload
+func (ddb *DynaPartition) TouchWithContext(ctx context.Context, sortKey string, ttl time.Duration, previous *KVPair) (*KVPair, error) {
+	return ddb.table.TouchWithContext(ctx, ddb.partition, sortKey, ttl, previous)
+}
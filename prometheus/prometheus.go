@@ -0,0 +1,150 @@
+// Package prometheus provides dynastore hooks which record Prometheus metrics for each request.
+//
+// Metrics are labelled by table and operation, partitions are deliberately not used as labels to keep the
+// cardinality of the metrics bounded.
+//
+// Use dynastore.ChainHooks to combine these hooks with tracing or logging hooks.
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/wolfeidau/dynastore"
+)
+
+const defaultNamespace = "dynastore"
+
+// Option assign various settings to the collectors
+type Option func(cfg *config)
+
+type config struct {
+	namespace string
+	buckets   []float64
+}
+
+// WithNamespace the namespace prefixed to each metric name, this defaults to dynastore
+func WithNamespace(namespace string) Option {
+	return func(cfg *config) {
+		cfg.namespace = namespace
+	}
+}
+
+// WithDurationBuckets the buckets used by the request duration histogram
+func WithDurationBuckets(buckets []float64) Option {
+	return func(cfg *config) {
+		cfg.buckets = buckets
+	}
+}
+
+type collectors struct {
+	requests          *prom.CounterVec
+	duration          *prom.HistogramVec
+	conditionFailures *prom.CounterVec
+	throttled         *prom.CounterVec
+	consumedCapacity  *prom.CounterVec
+	listPageItems     *prom.HistogramVec
+}
+
+// NewHooks create the collectors, register them with the registerer provided and return store hooks
+// which record metrics for each request sent by dynastore
+func NewHooks(reg prom.Registerer, opts ...Option) (*dynastore.StoreHooks, error) {
+	cfg := &config{
+		namespace: defaultNamespace,
+		buckets:   prom.DefBuckets,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	labels := []string{"table", "operation"}
+
+	c := &collectors{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "requests_total",
+			Help:      "The number of requests sent to DynamoDB.",
+		}, labels),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "request_duration_seconds",
+			Help:      "The duration of requests sent to DynamoDB.",
+			Buckets:   cfg.buckets,
+		}, labels),
+		conditionFailures: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "condition_failures_total",
+			Help:      "The number of conditional requests which failed as the key existed or was modified.",
+		}, labels),
+		throttled: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "throttled_requests_total",
+			Help:      "The number of requests which were throttled by DynamoDB.",
+		}, labels),
+		consumedCapacity: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "consumed_capacity_units_total",
+			Help:      "The capacity units consumed by requests sent to DynamoDB.",
+		}, []string{"table", "index", "operation"}),
+		listPageItems: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "list_page_items",
+			Help:      "The number of items returned per ListPage.",
+			Buckets:   prom.ExponentialBuckets(1, 4, 6),
+		}, []string{"table"}),
+	}
+
+	for _, collector := range []prom.Collector{
+		c.requests, c.duration, c.conditionFailures, c.throttled, c.consumedCapacity, c.listPageItems,
+	} {
+		if err := reg.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register collector: %w", err)
+		}
+	}
+
+	return &dynastore.StoreHooks{
+		RequestCompleted: c.requestCompleted,
+	}, nil
+}
+
+func (c *collectors) requestCompleted(ctx context.Context, info *dynastore.RequestInfo) {
+	c.requests.WithLabelValues(info.TableName, info.Operation).Inc()
+	c.duration.WithLabelValues(info.TableName, info.Operation).Observe(info.Duration.Seconds())
+
+	if info.ConditionFailed {
+		c.conditionFailures.WithLabelValues(info.TableName, info.Operation).Inc()
+	}
+
+	if isThrottled(info.Err) {
+		c.throttled.WithLabelValues(info.TableName, info.Operation).Inc()
+	}
+
+	for _, cc := range info.ConsumedCapacity {
+		if cc.CapacityUnits != nil {
+			c.consumedCapacity.WithLabelValues(info.TableName, info.IndexName, info.Operation).Add(*cc.CapacityUnits)
+		}
+	}
+
+	if info.Operation == "ListPage" && info.Err == nil {
+		c.listPageItems.WithLabelValues(info.TableName).Observe(float64(info.ItemCount))
+	}
+}
+
+func isThrottled(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException, dynamodb.ErrCodeRequestLimitExceeded, "ThrottlingException":
+		return true
+	}
+
+	return false
+}
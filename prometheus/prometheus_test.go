@@ -0,0 +1,83 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/wolfeidau/dynastore"
+)
+
+func TestNewHooks(t *testing.T) {
+	reg := prom.NewPedanticRegistry()
+
+	hooks, err := NewHooks(reg)
+	if err != nil {
+		t.Fatalf("NewHooks() error = %v", err)
+	}
+
+	for _, info := range []*dynastore.RequestInfo{
+		{
+			Operation:        "ListPage",
+			TableName:        "testing",
+			IndexName:        "idx_created",
+			PartitionKey:     "agent",
+			Duration:         10 * time.Millisecond,
+			ItemCount:        3,
+			ConsumedCapacity: []*dynamodb.ConsumedCapacity{{CapacityUnits: aws.Float64(0.5)}},
+		},
+		{
+			Operation:       "AtomicPut",
+			TableName:       "testing",
+			PartitionKey:    "agent",
+			Err:             awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "failed", nil),
+			ConditionFailed: true,
+		},
+		{
+			Operation:    "Get",
+			TableName:    "testing",
+			PartitionKey: "agent",
+			Err:          awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil),
+		},
+	} {
+		hooks.RequestCompleted(context.Background(), info)
+	}
+
+	c, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	if len(c) != 6 {
+		t.Errorf("Gather() metrics = %d, want 6", len(c))
+	}
+
+	tests := []struct {
+		name   string
+		metric string
+		want   int
+	}{
+		{name: "should count requests", metric: "dynastore_requests_total", want: 3},
+		{name: "should observe duration", metric: "dynastore_request_duration_seconds", want: 3},
+		{name: "should count condition failures", metric: "dynastore_condition_failures_total", want: 1},
+		{name: "should count throttled requests", metric: "dynastore_throttled_requests_total", want: 1},
+		{name: "should count consumed capacity", metric: "dynastore_consumed_capacity_units_total", want: 1},
+		{name: "should observe list page items", metric: "dynastore_list_page_items", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testutil.GatherAndCount(reg, tt.metric)
+			if err != nil {
+				t.Fatalf("GatherAndCount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GatherAndCount() = %d, want %d series", got, tt.want)
+			}
+		})
+	}
+}
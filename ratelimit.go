@@ -0,0 +1,161 @@
+package dynastore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// capacityLimiter is a token bucket measured in DynamoDB capacity units.
+//
+// As the cost of a request isn't known until the response is returned, callers wait until the
+// bucket has a positive balance, dispatch the request then debit the capacity actually consumed.
+// This may leave the bucket in debt which is repaid before the next request is permitted.
+type capacityLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // units added per second
+	burst  float64 // maximum units held in the bucket
+	tokens float64
+	last   time.Time
+}
+
+func newCapacityLimiter(rate, burst float64) *capacityLimiter {
+	if burst < rate {
+		burst = rate
+	}
+
+	return &capacityLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until the bucket has a positive balance or the context is done
+func (cl *capacityLimiter) wait(ctx context.Context) error {
+	for {
+		delay := cl.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// consume debit the units consumed by a request from the bucket
+func (cl *capacityLimiter) consume(units float64) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.refill(time.Now())
+	cl.tokens -= units
+}
+
+// reserve returns how long to wait before the bucket has a positive balance
+func (cl *capacityLimiter) reserve() time.Duration {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.refill(time.Now())
+
+	if cl.tokens > 0 {
+		return 0
+	}
+
+	// wait long enough to bring the balance back above zero
+	return time.Duration((-cl.tokens/cl.rate)*float64(time.Second)) + time.Millisecond
+}
+
+func (cl *capacityLimiter) refill(now time.Time) {
+	elapsed := now.Sub(cl.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	cl.last = now
+
+	cl.tokens += elapsed * cl.rate
+	if cl.tokens > cl.burst {
+		cl.tokens = cl.burst
+	}
+}
+
+// capacityUnits sum the capacity units in the consumed capacity returned by DynamoDB
+func capacityUnits(consumed ...*dynamodb.ConsumedCapacity) float64 {
+	var units float64
+
+	for _, cc := range consumed {
+		if cc != nil {
+			units += aws.Float64Value(cc.CapacityUnits)
+		}
+	}
+
+	return units
+}
+
+// tableLimiters the capacity limiters configured for a table and its indexes
+type tableLimiters struct {
+	read    *capacityLimiter
+	write   *capacityLimiter
+	indexes map[string]*capacityLimiter
+}
+
+func newTableLimiters(tableOptions *TableOptions) *tableLimiters {
+	limiters := &tableLimiters{indexes: make(map[string]*capacityLimiter)}
+
+	if tableOptions.readCapacityLimit > 0 {
+		limiters.read = newCapacityLimiter(tableOptions.readCapacityLimit, tableOptions.readCapacityLimit)
+	}
+
+	if tableOptions.writeCapacityLimit > 0 {
+		limiters.write = newCapacityLimiter(tableOptions.writeCapacityLimit, tableOptions.writeCapacityLimit)
+	}
+
+	for name, limit := range tableOptions.indexReadLimits {
+		if limit > 0 {
+			limiters.indexes[name] = newCapacityLimiter(limit, limit)
+		}
+	}
+
+	return limiters
+}
+
+// forInput the limiter for the table or index read or written by the request
+func (tl *tableLimiters) forInput(input interface{}) *capacityLimiter {
+	if tl == nil {
+		return nil
+	}
+
+	switch v := input.(type) {
+	case *dynamodb.GetItemInput:
+		return tl.read
+	case *dynamodb.QueryInput:
+		return tl.forRead(aws.StringValue(v.IndexName))
+	case *dynamodb.ScanInput:
+		return tl.forRead(aws.StringValue(v.IndexName))
+	case *dynamodb.UpdateItemInput, *dynamodb.DeleteItemInput, *dynamodb.PutItemInput, *dynamodb.BatchWriteItemInput:
+		return tl.write
+	}
+
+	return nil
+}
+
+func (tl *tableLimiters) forRead(indexName string) *capacityLimiter {
+	if limiter, ok := tl.indexes[indexName]; ok && indexName != "" {
+		return limiter
+	}
+
+	return tl.read
+}
@@ -0,0 +1,67 @@
+package dynastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func Test_tableLimiters_forInput(t *testing.T) {
+	limiters := newTableLimiters(NewTableOptions(
+		TableWithReadCapacityLimit(10),
+		TableWithWriteCapacityLimit(5),
+		TableWithIndexReadCapacityLimit("idx_created", 2),
+	))
+
+	tests := []struct {
+		name  string
+		input interface{}
+		want  *capacityLimiter
+	}{
+		{name: "should limit get with table read", input: &dynamodb.GetItemInput{}, want: limiters.read},
+		{name: "should limit query with table read", input: &dynamodb.QueryInput{}, want: limiters.read},
+		{name: "should limit query with index read", input: &dynamodb.QueryInput{IndexName: aws.String("idx_created")}, want: limiters.indexes["idx_created"]},
+		{name: "should limit query of other index with table read", input: &dynamodb.QueryInput{IndexName: aws.String("idx_other")}, want: limiters.read},
+		{name: "should limit scan with table read", input: &dynamodb.ScanInput{}, want: limiters.read},
+		{name: "should limit update with table write", input: &dynamodb.UpdateItemInput{}, want: limiters.write},
+		{name: "should limit batch write with table write", input: &dynamodb.BatchWriteItemInput{}, want: limiters.write},
+		{name: "should not limit other requests", input: &dynamodb.DescribeTableInput{}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limiters.forInput(tt.input); got != tt.want {
+				t.Errorf("forInput() = %p, want %p", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sendTableConsumesCapacity(t *testing.T) {
+	tbl := NewWithClient(nil, defaultHooks).Table("testing", TableWithReadCapacityLimit(10))
+
+	_, err := sendTable(context.Background(), tbl, &dynamodb.QueryInput{}, func(ctx aws.Context, in *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+		return &dynamodb.QueryOutput{ConsumedCapacity: &dynamodb.ConsumedCapacity{CapacityUnits: aws.Float64(25)}}, nil
+	})
+	if err != nil {
+		t.Fatalf("sendTable() error = %v", err)
+	}
+
+	// the bucket starts full with 10 units so consuming 25 leaves it in debt
+	if delay := tbl.limiters.read.reserve(); delay == 0 {
+		t.Errorf("reserve() = %v, want a delay", delay)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = sendTable(ctx, tbl, &dynamodb.QueryInput{}, func(ctx aws.Context, in *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+		t.Fatal("request sent while the limiter is in debt")
+		return nil, nil
+	})
+	if err != context.Canceled {
+		t.Errorf("sendTable() error = %v, want %v", err, context.Canceled)
+	}
+}
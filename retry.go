@@ -0,0 +1,83 @@
+package dynastore
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	defaultRetryBaseDelay = 50 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryPolicy controls how dynastore retries requests which fail with a throttling or transient error,
+// this is in addition to any retries performed by the AWS SDK client.
+//
+// Conditional check failures are never retried.
+type RetryPolicy struct {
+	// MaxAttempts the maximum number of times a request is sent, one disables retries
+	MaxAttempts int
+	// BaseDelay the delay before the first retry, this doubles with each attempt
+	BaseDelay time.Duration
+	// MaxDelay the maximum delay between attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy the policy used by a session, this leaves retries to the AWS SDK client
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 1,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+	}
+}
+
+// delay the jittered delay before the retry following the given attempt, starting at 1
+func (rp RetryPolicy) delay(attempt int) time.Duration {
+	return backoffDelay(rp.BaseDelay, rp.MaxDelay, attempt)
+}
+
+// retryPolicy the policy for the operation, falling back to the session policy
+func (ds *DynaSession) retryPolicy(operation string) RetryPolicy {
+	if policy, ok := ds.operationRetryPolicies[operation]; ok {
+		return policy
+	}
+
+	return ds.defaultRetryPolicy
+}
+
+// isRetryable the error is caused by throttling or a transient server error
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if _, ok := conditionFailed(err); ok {
+		return false
+	}
+
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+
+	switch awsErr.Code() {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException,
+		dynamodb.ErrCodeRequestLimitExceeded,
+		dynamodb.ErrCodeInternalServerError,
+		"ThrottlingException",
+		"ServiceUnavailable":
+		return true
+	}
+
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		return reqErr.StatusCode() >= http.StatusInternalServerError
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func Test_isRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "should not retry success", err: nil, want: false},
+		{name: "should retry throttling", err: awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil), want: true},
+		{name: "should retry request limit", err: awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "limited", nil), want: true},
+		{name: "should retry server error", err: awserr.NewRequestFailure(awserr.New("Unknown", "failed", nil), 503, "id"), want: true},
+		{name: "should retry wrapped throttling", err: fmt.Errorf("failed: %w", awserr.New("ThrottlingException", "throttled", nil)), want: true},
+		{name: "should not retry condition failure", err: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "failed", nil), want: false},
+		{name: "should not retry validation", err: awserr.NewRequestFailure(awserr.New("ValidationException", "invalid", nil), 400, "id"), want: false},
+		{name: "should not retry other errors", err: errors.New("failed"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sendRetry(t *testing.T) {
+	throttled := awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)
+	conditionFailed := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "failed", nil)
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		options      []SessionOption
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{
+			name:         "should not retry by default",
+			errs:         []error{throttled, nil},
+			wantErr:      throttled,
+			wantAttempts: 1,
+		},
+		{
+			name:         "should retry throttling",
+			options:      []SessionOption{SessionWithRetryPolicy(policy)},
+			errs:         []error{throttled, throttled, nil},
+			wantAttempts: 3,
+		},
+		{
+			name:         "should stop after max attempts",
+			options:      []SessionOption{SessionWithRetryPolicy(policy)},
+			errs:         []error{throttled, throttled, throttled, nil},
+			wantErr:      throttled,
+			wantAttempts: 3,
+		},
+		{
+			name:         "should never retry condition failures",
+			options:      []SessionOption{SessionWithRetryPolicy(policy)},
+			errs:         []error{conditionFailed, nil},
+			wantErr:      conditionFailed,
+			wantAttempts: 1,
+		},
+		{
+			name:         "should use the operation override",
+			options:      []SessionOption{SessionWithRetryPolicy(policy), SessionWithOperationRetryPolicy("Get", RetryPolicy{MaxAttempts: 1})},
+			errs:         []error{throttled, nil},
+			wantErr:      throttled,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts []int
+
+			ds := NewWithClient(nil, &StoreHooks{
+				RequestCompleted: func(ctx context.Context, info *RequestInfo) {
+					attempts = append(attempts, info.Attempt)
+				},
+			}, tt.options...)
+
+			ctx := setOperationName(context.Background(), "Get")
+
+			_, err := send(ctx, ds, "testing", &dynamodb.GetItemInput{}, func(ctx aws.Context, in *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{}, tt.errs[len(attempts)]
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("send() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(attempts) != tt.wantAttempts {
+				t.Errorf("send() attempts = %v, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package dynastore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// ScanPageFunc is invoked with each page read by a scan, returning an error will stop the scan.
+//
+// When a scan is split into more than one segment this is called concurrently by each segment worker.
+type ScanPageFunc func(page *ScanPage) error
+
+// Scan read every record in the table across all partitions, passing each page to the function provided.
+//
+// Each segment of the scan is read by a separate worker, with every page carrying the segment number and
+// a LastKey which can be used with ScanWithStartKey to resume that segment if the scan is interrupted.
+func (dt *DynaTable) Scan(ctx context.Context, fn ScanPageFunc, options ...ScanOption) (err error) {
+	scanOptions := NewScanOptions(options...)
+
+	ctx, done := dt.session.startOperation(ctx, "Scan", dt.GetTableName(), "")
+	defer func() { done(err) }()
+
+	segments, err := resolveScanSegments(scanOptions)
+	if err != nil {
+		return err
+	}
+
+	expr, hasExpr, err := buildScanExpression(&dt.attributes, scanOptions, dt.now())
+	if err != nil {
+		return fmt.Errorf("failed to build scan expression: %w", err)
+	}
+
+	var limiter *capacityLimiter
+
+	if scanOptions.readCapacityLimit > 0 {
+		limiter = newCapacityLimiter(scanOptions.readCapacityLimit, scanOptions.readCapacityLimit)
+	}
+
+	// every start key is decoded before any segment is started so a bad key doesn't leave segments running
+	inputs := make([]*dynamodb.ScanInput, len(segments))
+
+	for n, segment := range segments {
+		input := &dynamodb.ScanInput{
+			TableName:              aws.String(dt.GetTableName()),
+			Segment:                aws.Int64(int64(segment)),
+			TotalSegments:          aws.Int64(int64(scanOptions.totalSegments)),
+			ConsistentRead:         aws.Bool(scanOptions.consistent),
+			Limit:                  scanOptions.limit,
+			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		}
+
+		if hasExpr {
+			input.ExpressionAttributeNames = expr.Names()
+			input.ExpressionAttributeValues = expr.Values()
+			input.FilterExpression = expr.Filter()
+			input.ProjectionExpression = expr.Projection()
+		}
+
+		// avoid either a nil or empty value
+		if startKey := scanOptions.startKeys[segment]; startKey != "" {
+			input.ExclusiveStartKey, err = dt.decodePageToken(scanScope(segment, scanOptions.totalSegments), startKey)
+			if err != nil {
+				return fmt.Errorf("failed to decode key for segment %d: %w", segment, err)
+			}
+		}
+
+		inputs[n] = input
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(inputs))
+
+	var wg sync.WaitGroup
+
+	for _, input := range inputs {
+		wg.Add(1)
+
+		go func(input *dynamodb.ScanInput) {
+			defer wg.Done()
+
+			if err := dt.scanSegment(ctx, input, limiter, fn); err != nil {
+				errs <- err
+				cancel() // stop the other segments
+			}
+		}(input)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	// the first error is the one which stopped the scan
+	if err, ok := <-errs; ok {
+		return err
+	}
+
+	return nil
+}
+
+func (dt *DynaTable) scanSegment(ctx context.Context, input *dynamodb.ScanInput, limiter *capacityLimiter, fn ScanPageFunc) error {
+	for {
+		if limiter != nil {
+			if err := limiter.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		res, err := sendTable(ctx, dt, input, dt.session.ScanWithContext)
+		if err != nil {
+			return fmt.Errorf("failed to scan segment %d: %w", aws.Int64Value(input.Segment), err)
+		}
+
+		if limiter != nil {
+			limiter.consume(capacityUnits(res.ConsumedCapacity))
+		}
+
+		page := &ScanPage{
+			Segment:       int(aws.Int64Value(input.Segment)),
+			TotalSegments: int(aws.Int64Value(input.TotalSegments)),
+			Keys:          make([]*KVPair, len(res.Items)),
+		}
+
+		for n, item := range res.Items {
+			page.Keys[n], err = dt.attributes.decodeItem(item)
+			if err != nil {
+				return fmt.Errorf("failed to decode item: %w", err)
+			}
+		}
+
+		if len(res.LastEvaluatedKey) != 0 {
+			page.LastKey, err = dt.encodePageToken(scanScope(page.Segment, page.TotalSegments), res.LastEvaluatedKey)
+			if err != nil {
+				return fmt.Errorf("failed to encode key: %w", err)
+			}
+		}
+
+		err = fn(page)
+		if err != nil {
+			return err
+		}
+
+		if len(res.LastEvaluatedKey) == 0 {
+			return nil
+		}
+
+		input.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+}
+
+// resolveScanSegments return the list of segments to scan, defaulting to all of them
+func resolveScanSegments(scanOptions *ScanOptions) ([]int, error) {
+	if scanOptions.totalSegments < 1 {
+		return nil, fmt.Errorf("total segments must be at least 1: %w", ErrInvalidScanSegments)
+	}
+
+	if len(scanOptions.segments) == 0 {
+		segments := make([]int, scanOptions.totalSegments)
+		for n := range segments {
+			segments[n] = n
+		}
+
+		return segments, nil
+	}
+
+	seen := make(map[int]bool, len(scanOptions.segments))
+
+	for _, segment := range scanOptions.segments {
+		if segment < 0 || segment >= scanOptions.totalSegments {
+			return nil, fmt.Errorf("segment %d not in range of %d segments: %w", segment, scanOptions.totalSegments, ErrInvalidScanSegments)
+		}
+
+		if seen[segment] {
+			return nil, fmt.Errorf("segment %d listed more than once: %w", segment, ErrInvalidScanSegments)
+		}
+
+		seen[segment] = true
+	}
+
+	return scanOptions.segments, nil
+}
+
+func buildScanExpression(attributes *AttributeNames, scanOptions *ScanOptions, now time.Time) (dexp.Expression, bool, error) {
+	filter := scanOptions.filter
+
+	// expired records are filtered by DynamoDB along with any filter provided
+	if !scanOptions.includeExpired {
+		cond := attributes.notExpiredCondition(now)
+		if filter != nil {
+			cond = dexp.And(*filter, cond)
+		}
+		filter = &cond
+	}
+
+	if filter == nil && len(scanOptions.projection) == 0 {
+		return dexp.Expression{}, false, nil
+	}
+
+	builder := dexp.NewBuilder()
+
+	if filter != nil {
+		builder = builder.WithFilter(*filter)
+	}
+
+	if len(scanOptions.projection) != 0 {
+		// the key attributes are always required to decode the record
+		proj := dexp.NamesList(dexp.Name(attributes.PartitionKey), dexp.Name(attributes.SortKey))
+
+		for _, attr := range scanOptions.projection {
+			if attributes.isKey(attr) {
+				continue
+			}
+			proj = proj.AddNames(dexp.Name(attr))
+		}
+
+		builder = builder.WithProjection(proj)
+	}
+
+	expr, err := builder.Build()
+	if err != nil {
+		return dexp.Expression{}, false, err
+	}
+
+	return expr, true, nil
+}
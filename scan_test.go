@@ -0,0 +1,88 @@
+package dynastore
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func Test_buildScanExpression(t *testing.T) {
+	attributes := DefaultAttributeNames()
+
+	tests := []struct {
+		name       string
+		options    []ScanOption
+		wantFilter []string
+		wantExpr   bool
+	}{
+		{
+			name:       "should filter expired records by default",
+			wantFilter: []string{"attribute_not_exists (expires)", "expires >"},
+			wantExpr:   true,
+		},
+		{
+			name:       "should combine the expiry filter with the filter provided",
+			options:    []ScanOption{ScanWithFilter(dexp.Name("owner").Equal(dexp.Value("agent")))},
+			wantFilter: []string{"owner =", "AND", "attribute_not_exists (expires)"},
+			wantExpr:   true,
+		},
+		{
+			name:     "should not build an expression when including expired records",
+			options:  []ScanOption{ScanIncludeExpired()},
+			wantExpr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, hasExpr, err := buildScanExpression(&attributes, NewScanOptions(tt.options...), time.Now())
+			if err != nil {
+				t.Fatalf("buildScanExpression() error = %v", err)
+			}
+			if hasExpr != tt.wantExpr {
+				t.Fatalf("buildScanExpression() hasExpr = %v, want %v", hasExpr, tt.wantExpr)
+			}
+			if !hasExpr {
+				return
+			}
+
+			filter := *expr.Filter()
+			for alias, name := range expr.Names() {
+				filter = strings.ReplaceAll(filter, alias, *name)
+			}
+
+			for _, want := range tt.wantFilter {
+				if !strings.Contains(filter, want) {
+					t.Errorf("buildScanExpression() filter = %q, want %s", filter, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDynaTable_ScanInvalidStartKey(t *testing.T) {
+	var requests int32
+
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		atomic.AddInt32(&requests, 1)
+		return map[string]interface{}{"Items": []interface{}{}}
+	})
+
+	tbl := NewWithClient(svc, defaultHooks).Table("testing")
+
+	// a bad start key for a later segment stops the scan before any segment is started
+	err := tbl.Scan(context.Background(), func(page *ScanPage) error {
+		t.Error("Scan() called fn with an invalid start key")
+		return nil
+	}, ScanWithTotalSegments(4), ScanWithStartKey(3, "not a key"))
+	if err == nil {
+		t.Fatal("Scan() error = nil, want an error decoding the start key")
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("requests = %d, want 0", n)
+	}
+}
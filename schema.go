@@ -0,0 +1,590 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const indexPollInterval = 2 * time.Second
+
+// KeyAttribute describes an attribute used in the key of a table or index
+type KeyAttribute struct {
+	Name string
+	Type string // one of the dynamodb.ScalarAttributeType values, defaults to S
+}
+
+// Throughput provisioned read and write capacity for a table or global index
+type Throughput struct {
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+}
+
+// LocalIndexSchema describes a local secondary index, these share the partition key of the table.
+type LocalIndexSchema struct {
+	Name             string
+	SortKey          KeyAttribute
+	ProjectionType   string // one of the dynamodb.ProjectionType values, defaults to ALL
+	NonKeyAttributes []string
+}
+
+// GlobalIndexSchema describes a global secondary index, the sort key is optional.
+type GlobalIndexSchema struct {
+	Name                  string
+	PartitionKey          KeyAttribute
+	SortKey               KeyAttribute
+	ProjectionType        string // one of the dynamodb.ProjectionType values, defaults to ALL
+	NonKeyAttributes      []string
+	ProvisionedThroughput *Throughput // required when the table uses provisioned billing
+}
+
+// TableSchema describes a dynastore table, this is used to create or verify a table with EnsureTable.
+type TableSchema struct {
+	TableName             string
+	PartitionKey          KeyAttribute
+	SortKey               KeyAttribute
+	LocalIndexes          []LocalIndexSchema
+	GlobalIndexes         []GlobalIndexSchema
+	BillingMode           string      // one of the dynamodb.BillingMode values, defaults to PAY_PER_REQUEST
+	ProvisionedThroughput *Throughput // required when using provisioned billing
+	TTLAttribute          string      // the time to live attribute, TTL is not enabled if this is empty
+}
+
+// NewTableSchema create a table schema with the default dynastore key and TTL attributes using on demand billing
+func NewTableSchema(tableName string) *TableSchema {
+	return &TableSchema{
+		TableName:    tableName,
+		PartitionKey: KeyAttribute{Name: DefaultPartitionKeyAttribute, Type: dynamodb.ScalarAttributeTypeS},
+		SortKey:      KeyAttribute{Name: DefaultSortKeyAttribute, Type: dynamodb.ScalarAttributeTypeS},
+		BillingMode:  dynamodb.BillingModePayPerRequest,
+		TTLAttribute: DefaultExpiresAttribute,
+	}
+}
+
+// SchemaConflictError is returned by EnsureTable when an existing table doesn't match the schema
+type SchemaConflictError struct {
+	TableName   string
+	Differences []string
+}
+
+func (e *SchemaConflictError) Error() string {
+	return fmt.Sprintf("table %q conflicts with schema:\n  %s", e.TableName, strings.Join(e.Differences, "\n  "))
+}
+
+func (e *SchemaConflictError) Unwrap() error {
+	return ErrSchemaConflict
+}
+
+// EnsureTable create the table described by the schema if it is missing and wait for it to be active, then
+// enable TTL and add any missing global indexes.
+//
+// If the table exists and conflicts with the schema, in a way which can't be updated, a *SchemaConflictError
+// is returned listing the differences.
+func (ds *DynaSession) EnsureTable(ctx context.Context, schema *TableSchema) (err error) {
+	ctx, done := ds.startOperation(ctx, "EnsureTable", schema.TableName, "")
+	defer func() { done(err) }()
+
+	err = schema.validate()
+	if err != nil {
+		return err
+	}
+
+	desc, err := ds.describeTable(ctx, schema.TableName)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case desc == nil:
+		desc, err = ds.createTable(ctx, schema)
+		if err != nil {
+			return err
+		}
+	case aws.StringValue(desc.TableStatus) != dynamodb.TableStatusActive:
+		// the table may still be being created or updated by another process
+		desc, err = ds.waitForTable(ctx, schema.TableName)
+		if err != nil {
+			return err
+		}
+	}
+
+	missing, differences := diffTableSchema(schema, desc)
+	if len(differences) != 0 {
+		return &SchemaConflictError{TableName: schema.TableName, Differences: differences}
+	}
+
+	// global indexes can only be added one at a time
+	for _, gsi := range missing {
+		err = ds.createGlobalIndex(ctx, schema, gsi)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ds.ensureTTL(ctx, schema)
+}
+
+func (ds *DynaSession) describeTable(ctx context.Context, tableName string) (*dynamodb.TableDescription, error) {
+	describeTable := &dynamodb.DescribeTableInput{TableName: aws.String(tableName)}
+
+	res, err := send(ctx, ds, tableName, describeTable, ds.DescribeTableWithContext)
+	if err != nil {
+		if isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe table: %w", err)
+	}
+
+	return res.Table, nil
+}
+
+func (ds *DynaSession) createTable(ctx context.Context, schema *TableSchema) (*dynamodb.TableDescription, error) {
+	createTable := schema.createTableInput()
+
+	_, err := send(ctx, ds, schema.TableName, createTable, ds.CreateTableWithContext)
+	if err != nil && !isAWSErrorCode(err, dynamodb.ErrCodeResourceInUseException) {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	// the table may have been created concurrently, either way wait for it to be active
+	return ds.waitForTable(ctx, schema.TableName)
+}
+
+// waitForTable wait for the table to be active then return its description
+func (ds *DynaSession) waitForTable(ctx context.Context, tableName string) (*dynamodb.TableDescription, error) {
+	err := ds.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for table: %w", err)
+	}
+
+	desc, err := ds.describeTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if desc == nil {
+		return nil, fmt.Errorf("table %q not found after waiting for it to be active", tableName)
+	}
+
+	return desc, nil
+}
+
+func (ds *DynaSession) createGlobalIndex(ctx context.Context, schema *TableSchema, gsi GlobalIndexSchema) error {
+	updateTable := schema.createGlobalIndexInput(gsi)
+
+	_, err := send(ctx, ds, schema.TableName, updateTable, ds.UpdateTableWithContext)
+	if err != nil {
+		return fmt.Errorf("failed to create global index %s: %w", gsi.Name, err)
+	}
+
+	return ds.waitForIndexes(ctx, schema.TableName)
+}
+
+// waitForIndexes poll the table until it and all of the global indexes are active
+func (ds *DynaSession) waitForIndexes(ctx context.Context, tableName string) error {
+	for {
+		desc, err := ds.describeTable(ctx, tableName)
+		if err != nil {
+			return err
+		}
+
+		if desc == nil {
+			return fmt.Errorf("table %q not found", tableName)
+		}
+
+		active := aws.StringValue(desc.TableStatus) == dynamodb.TableStatusActive
+
+		for _, gsi := range desc.GlobalSecondaryIndexes {
+			if aws.StringValue(gsi.IndexStatus) != dynamodb.IndexStatusActive {
+				active = false
+			}
+		}
+
+		if active {
+			return nil
+		}
+
+		timer := time.NewTimer(indexPollInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (ds *DynaSession) ensureTTL(ctx context.Context, schema *TableSchema) error {
+	if schema.TTLAttribute == "" {
+		return nil
+	}
+
+	describeTTL := &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(schema.TableName)}
+
+	res, err := send(ctx, ds, schema.TableName, describeTTL, ds.DescribeTimeToLiveWithContext)
+	if err != nil {
+		return fmt.Errorf("failed to describe ttl: %w", err)
+	}
+
+	if desc := res.TimeToLiveDescription; desc != nil {
+		switch aws.StringValue(desc.TimeToLiveStatus) {
+		case dynamodb.TimeToLiveStatusEnabled, dynamodb.TimeToLiveStatusEnabling:
+			if name := aws.StringValue(desc.AttributeName); name != schema.TTLAttribute {
+				return &SchemaConflictError{
+					TableName:   schema.TableName,
+					Differences: []string{fmt.Sprintf("ttl attribute: want %s, got %s", schema.TTLAttribute, name)},
+				}
+			}
+			return nil
+		}
+	}
+
+	updateTTL := &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(schema.TableName),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String(schema.TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	}
+
+	_, err = send(ctx, ds, schema.TableName, updateTTL, ds.UpdateTimeToLiveWithContext)
+	if err != nil {
+		return fmt.Errorf("failed to update ttl: %w", err)
+	}
+
+	return nil
+}
+
+func (ts *TableSchema) validate() error {
+	if ts.TableName == "" {
+		return fmt.Errorf("table name is required: %w", ErrInvalidSchema)
+	}
+
+	if ts.PartitionKey.Name == "" {
+		return fmt.Errorf("partition key is required: %w", ErrInvalidSchema)
+	}
+
+	if ts.billingMode() == dynamodb.BillingModeProvisioned && ts.ProvisionedThroughput == nil {
+		return fmt.Errorf("provisioned throughput is required for provisioned billing: %w", ErrInvalidSchema)
+	}
+
+	// an attribute can only have one type across the table and all the indexes
+	types := map[string]string{}
+
+	for _, attr := range ts.keyAttributes() {
+		if t, ok := types[attr.Name]; ok && t != attr.attributeType() {
+			return fmt.Errorf("attribute %s defined as both %s and %s: %w", attr.Name, t, attr.attributeType(), ErrInvalidSchema)
+		}
+		types[attr.Name] = attr.attributeType()
+	}
+
+	names := map[string]bool{}
+
+	for _, lsi := range ts.LocalIndexes {
+		if lsi.Name == "" || lsi.SortKey.Name == "" {
+			return fmt.Errorf("local indexes require a name and sort key: %w", ErrInvalidSchema)
+		}
+		if names[lsi.Name] {
+			return fmt.Errorf("index %s defined more than once: %w", lsi.Name, ErrInvalidSchema)
+		}
+		names[lsi.Name] = true
+	}
+
+	for _, gsi := range ts.GlobalIndexes {
+		if gsi.Name == "" || gsi.PartitionKey.Name == "" {
+			return fmt.Errorf("global indexes require a name and partition key: %w", ErrInvalidSchema)
+		}
+		if names[gsi.Name] {
+			return fmt.Errorf("index %s defined more than once: %w", gsi.Name, ErrInvalidSchema)
+		}
+		if ts.billingMode() == dynamodb.BillingModeProvisioned && gsi.ProvisionedThroughput == nil {
+			return fmt.Errorf("index %s requires provisioned throughput for provisioned billing: %w", gsi.Name, ErrInvalidSchema)
+		}
+		names[gsi.Name] = true
+	}
+
+	return nil
+}
+
+func (ts *TableSchema) billingMode() string {
+	if ts.BillingMode == "" {
+		return dynamodb.BillingModePayPerRequest
+	}
+
+	return ts.BillingMode
+}
+
+// keyAttributes all the key attributes used by the table and indexes
+func (ts *TableSchema) keyAttributes() []KeyAttribute {
+	attrs := []KeyAttribute{ts.PartitionKey, ts.SortKey}
+
+	for _, lsi := range ts.LocalIndexes {
+		attrs = append(attrs, lsi.SortKey)
+	}
+
+	for _, gsi := range ts.GlobalIndexes {
+		attrs = append(attrs, gsi.PartitionKey, gsi.SortKey)
+	}
+
+	return attrs
+}
+
+func (ts *TableSchema) createTableInput() *dynamodb.CreateTableInput {
+	createTable := &dynamodb.CreateTableInput{
+		TableName:            aws.String(ts.TableName),
+		KeySchema:            keySchema(ts.PartitionKey, ts.SortKey),
+		AttributeDefinitions: attributeDefinitions(ts.keyAttributes()...),
+		BillingMode:          aws.String(ts.billingMode()),
+	}
+
+	if ts.billingMode() == dynamodb.BillingModeProvisioned {
+		createTable.ProvisionedThroughput = ts.ProvisionedThroughput.provisionedThroughput()
+	}
+
+	for _, lsi := range ts.LocalIndexes {
+		createTable.LocalSecondaryIndexes = append(createTable.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName:  aws.String(lsi.Name),
+			KeySchema:  keySchema(ts.PartitionKey, lsi.SortKey),
+			Projection: projection(lsi.ProjectionType, lsi.NonKeyAttributes),
+		})
+	}
+
+	for _, gsi := range ts.GlobalIndexes {
+		index := &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(gsi.Name),
+			KeySchema:  keySchema(gsi.PartitionKey, gsi.SortKey),
+			Projection: projection(gsi.ProjectionType, gsi.NonKeyAttributes),
+		}
+
+		if ts.billingMode() == dynamodb.BillingModeProvisioned {
+			index.ProvisionedThroughput = gsi.ProvisionedThroughput.provisionedThroughput()
+		}
+
+		createTable.GlobalSecondaryIndexes = append(createTable.GlobalSecondaryIndexes, index)
+	}
+
+	return createTable
+}
+
+func (ts *TableSchema) createGlobalIndexInput(gsi GlobalIndexSchema) *dynamodb.UpdateTableInput {
+	create := &dynamodb.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String(gsi.Name),
+		KeySchema:  keySchema(gsi.PartitionKey, gsi.SortKey),
+		Projection: projection(gsi.ProjectionType, gsi.NonKeyAttributes),
+	}
+
+	// on demand tables reject indexes with provisioned throughput
+	if ts.billingMode() == dynamodb.BillingModeProvisioned {
+		create.ProvisionedThroughput = gsi.ProvisionedThroughput.provisionedThroughput()
+	}
+
+	return &dynamodb.UpdateTableInput{
+		TableName:                   aws.String(ts.TableName),
+		AttributeDefinitions:        attributeDefinitions(gsi.PartitionKey, gsi.SortKey),
+		GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{{Create: create}},
+	}
+}
+
+// diffTableSchema compare the schema with an existing table, returning the global indexes which are missing
+// and a description of any differences which can't be resolved by updating the table
+func diffTableSchema(ts *TableSchema, desc *dynamodb.TableDescription) ([]GlobalIndexSchema, []string) {
+	var differences []string
+
+	types := map[string]string{}
+	for _, def := range desc.AttributeDefinitions {
+		types[aws.StringValue(def.AttributeName)] = aws.StringValue(def.AttributeType)
+	}
+
+	diffKeys := func(label string, want []KeyAttribute, got []*dynamodb.KeySchemaElement) {
+		if w, g := describeKeys(want), describeKeySchema(got, types); w != g {
+			differences = append(differences, fmt.Sprintf("%s: want %s, got %s", label, w, g))
+		}
+	}
+
+	diffProjection := func(label, projectionType string, nonKeyAttributes []string, got *dynamodb.Projection) {
+		if w, g := describeProjection(projection(projectionType, nonKeyAttributes)), describeProjection(got); w != g {
+			differences = append(differences, fmt.Sprintf("%s projection: want %s, got %s", label, w, g))
+		}
+	}
+
+	diffKeys("table key", []KeyAttribute{ts.PartitionKey, ts.SortKey}, desc.KeySchema)
+
+	billingMode := dynamodb.BillingModeProvisioned
+	if desc.BillingModeSummary != nil && desc.BillingModeSummary.BillingMode != nil {
+		billingMode = aws.StringValue(desc.BillingModeSummary.BillingMode)
+	}
+
+	if billingMode != ts.billingMode() {
+		differences = append(differences, fmt.Sprintf("billing mode: want %s, got %s", ts.billingMode(), billingMode))
+	}
+
+	lsis := map[string]*dynamodb.LocalSecondaryIndexDescription{}
+	for _, lsi := range desc.LocalSecondaryIndexes {
+		lsis[aws.StringValue(lsi.IndexName)] = lsi
+	}
+
+	for _, lsi := range ts.LocalIndexes {
+		label := fmt.Sprintf("local index %s", lsi.Name)
+
+		existing, ok := lsis[lsi.Name]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("%s: missing, local indexes can only be added when the table is created", label))
+			continue
+		}
+
+		diffKeys(label+" key", []KeyAttribute{ts.PartitionKey, lsi.SortKey}, existing.KeySchema)
+		diffProjection(label, lsi.ProjectionType, lsi.NonKeyAttributes, existing.Projection)
+	}
+
+	gsis := map[string]*dynamodb.GlobalSecondaryIndexDescription{}
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		gsis[aws.StringValue(gsi.IndexName)] = gsi
+	}
+
+	var missing []GlobalIndexSchema
+
+	for _, gsi := range ts.GlobalIndexes {
+		label := fmt.Sprintf("global index %s", gsi.Name)
+
+		existing, ok := gsis[gsi.Name]
+		if !ok {
+			// check the key attributes of the new index don't clash with the existing table
+			for _, attr := range []KeyAttribute{gsi.PartitionKey, gsi.SortKey} {
+				if t, ok := types[attr.Name]; ok && attr.Name != "" && t != attr.attributeType() {
+					differences = append(differences, fmt.Sprintf("%s: attribute %s want type %s, got %s", label, attr.Name, attr.attributeType(), t))
+				}
+			}
+
+			missing = append(missing, gsi)
+			continue
+		}
+
+		diffKeys(label+" key", []KeyAttribute{gsi.PartitionKey, gsi.SortKey}, existing.KeySchema)
+		diffProjection(label, gsi.ProjectionType, gsi.NonKeyAttributes, existing.Projection)
+	}
+
+	return missing, differences
+}
+
+func (ka KeyAttribute) attributeType() string {
+	if ka.Type == "" {
+		return dynamodb.ScalarAttributeTypeS
+	}
+
+	return ka.Type
+}
+
+func (tp *Throughput) provisionedThroughput() *dynamodb.ProvisionedThroughput {
+	if tp == nil {
+		return nil
+	}
+
+	return &dynamodb.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(tp.ReadCapacityUnits),
+		WriteCapacityUnits: aws.Int64(tp.WriteCapacityUnits),
+	}
+}
+
+func keySchema(partitionKey, sortKey KeyAttribute) []*dynamodb.KeySchemaElement {
+	elements := []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String(partitionKey.Name), KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+
+	if sortKey.Name != "" {
+		elements = append(elements, &dynamodb.KeySchemaElement{AttributeName: aws.String(sortKey.Name), KeyType: aws.String(dynamodb.KeyTypeRange)})
+	}
+
+	return elements
+}
+
+// attributeDefinitions de-duplicated definitions for the key attributes provided
+func attributeDefinitions(attrs ...KeyAttribute) []*dynamodb.AttributeDefinition {
+	var (
+		defs []*dynamodb.AttributeDefinition
+		seen = map[string]bool{}
+	)
+
+	for _, attr := range attrs {
+		if attr.Name == "" || seen[attr.Name] {
+			continue
+		}
+
+		seen[attr.Name] = true
+
+		defs = append(defs, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(attr.Name),
+			AttributeType: aws.String(attr.attributeType()),
+		})
+	}
+
+	return defs
+}
+
+func projection(projectionType string, nonKeyAttributes []string) *dynamodb.Projection {
+	if projectionType == "" {
+		projectionType = dynamodb.ProjectionTypeAll
+	}
+
+	proj := &dynamodb.Projection{ProjectionType: aws.String(projectionType)}
+
+	if len(nonKeyAttributes) != 0 {
+		proj.NonKeyAttributes = aws.StringSlice(nonKeyAttributes)
+	}
+
+	return proj
+}
+
+func describeKeys(attrs []KeyAttribute) string {
+	var parts []string
+
+	for _, attr := range attrs {
+		if attr.Name != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", attr.Name, attr.attributeType()))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func describeKeySchema(elements []*dynamodb.KeySchemaElement, types map[string]string) string {
+	var parts []string
+
+	for _, el := range elements {
+		name := aws.StringValue(el.AttributeName)
+		parts = append(parts, fmt.Sprintf("%s (%s)", name, types[name]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func describeProjection(proj *dynamodb.Projection) string {
+	if proj == nil {
+		return dynamodb.ProjectionTypeAll
+	}
+
+	attrs := aws.StringValueSlice(proj.NonKeyAttributes)
+	if len(attrs) == 0 {
+		return aws.StringValue(proj.ProjectionType)
+	}
+
+	sort.Strings(attrs)
+
+	return fmt.Sprintf("%s [%s]", aws.StringValue(proj.ProjectionType), strings.Join(attrs, ", "))
+}
+
+func isAWSErrorCode(err error, code string) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code() == code
+	}
+
+	return false
+}
@@ -0,0 +1,153 @@
+package dynastore
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func testSchema() *TableSchema {
+	schema := NewTableSchema("testing")
+	schema.LocalIndexes = []LocalIndexSchema{
+		{Name: "idx_created", SortKey: KeyAttribute{Name: "created"}},
+	}
+	schema.GlobalIndexes = []GlobalIndexSchema{
+		{Name: "idx_global_1", PartitionKey: KeyAttribute{Name: "pk1"}, SortKey: KeyAttribute{Name: "sk1"}},
+	}
+
+	return schema
+}
+
+func Test_diffTableSchema(t *testing.T) {
+	created := testSchema().createTableInput()
+
+	matching := &dynamodb.TableDescription{
+		KeySchema:            created.KeySchema,
+		AttributeDefinitions: created.AttributeDefinitions,
+		BillingModeSummary:   &dynamodb.BillingModeSummary{BillingMode: aws.String(dynamodb.BillingModePayPerRequest)},
+		LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndexDescription{
+			{
+				IndexName:  created.LocalSecondaryIndexes[0].IndexName,
+				KeySchema:  created.LocalSecondaryIndexes[0].KeySchema,
+				Projection: created.LocalSecondaryIndexes[0].Projection,
+			},
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+			{
+				IndexName:  created.GlobalSecondaryIndexes[0].IndexName,
+				KeySchema:  created.GlobalSecondaryIndexes[0].KeySchema,
+				Projection: created.GlobalSecondaryIndexes[0].Projection,
+			},
+		},
+	}
+
+	withoutGlobalIndex := *matching
+	withoutGlobalIndex.GlobalSecondaryIndexes = nil
+
+	conflicting := *matching
+	conflicting.KeySchema = keySchema(KeyAttribute{Name: "pk"}, KeyAttribute{Name: "sk"})
+	conflicting.BillingModeSummary = nil
+	conflicting.LocalSecondaryIndexes = nil
+
+	tests := []struct {
+		name            string
+		desc            *dynamodb.TableDescription
+		wantMissing     []string
+		wantDifferences []string
+	}{
+		{
+			name: "should match existing table",
+			desc: matching,
+		},
+		{
+			name:        "should add missing global index",
+			desc:        &withoutGlobalIndex,
+			wantMissing: []string{"idx_global_1"},
+		},
+		{
+			name: "should describe conflicts",
+			desc: &conflicting,
+			wantDifferences: []string{
+				"table key: want id (S), name (S), got pk (), sk ()",
+				"billing mode: want PAY_PER_REQUEST, got PROVISIONED",
+				"local index idx_created: missing, local indexes can only be added when the table is created",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, differences := diffTableSchema(testSchema(), tt.desc)
+
+			var missingNames []string
+			for _, gsi := range missing {
+				missingNames = append(missingNames, gsi.Name)
+			}
+
+			if !reflect.DeepEqual(missingNames, tt.wantMissing) {
+				t.Errorf("diffTableSchema() missing = %v, want %v", missingNames, tt.wantMissing)
+			}
+			if !reflect.DeepEqual(differences, tt.wantDifferences) {
+				t.Errorf("diffTableSchema() differences = %q, want %q", differences, tt.wantDifferences)
+			}
+		})
+	}
+}
+
+func TestTableSchema_validate(t *testing.T) {
+	provisioned := testSchema()
+	provisioned.BillingMode = dynamodb.BillingModeProvisioned
+
+	clashingTypes := testSchema()
+	clashingTypes.GlobalIndexes[0].SortKey.Type = dynamodb.ScalarAttributeTypeN
+	clashingTypes.LocalIndexes[0].SortKey.Name = "sk1"
+
+	tests := []struct {
+		name    string
+		schema  *TableSchema
+		wantErr error
+	}{
+		{name: "should accept valid schema", schema: testSchema()},
+		{name: "should require table name", schema: &TableSchema{}, wantErr: ErrInvalidSchema},
+		{name: "should require provisioned throughput", schema: provisioned, wantErr: ErrInvalidSchema},
+		{name: "should reject attributes with more than one type", schema: clashingTypes, wantErr: ErrInvalidSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTableSchema_createGlobalIndexInput(t *testing.T) {
+	gsi := GlobalIndexSchema{
+		Name:                  "idx_global_1",
+		PartitionKey:          KeyAttribute{Name: "pk1"},
+		ProvisionedThroughput: &Throughput{ReadCapacityUnits: 1, WriteCapacityUnits: 1},
+	}
+
+	tests := []struct {
+		name           string
+		billingMode    string
+		wantThroughput bool
+	}{
+		{name: "should omit throughput for on demand billing", billingMode: dynamodb.BillingModePayPerRequest},
+		{name: "should include throughput for provisioned billing", billingMode: dynamodb.BillingModeProvisioned, wantThroughput: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := NewTableSchema("testing")
+			schema.BillingMode = tt.billingMode
+
+			got := schema.createGlobalIndexInput(gsi).GlobalSecondaryIndexUpdates[0].Create.ProvisionedThroughput
+			if (got != nil) != tt.wantThroughput {
+				t.Errorf("createGlobalIndexInput() throughput = %v, want throughput %v", got, tt.wantThroughput)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package dynastore
 
 import (
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -9,10 +11,35 @@ import (
 type DynaSession struct {
 	*dynamodb.DynamoDB
 	storeHooks *StoreHooks
+	clock      Clock
+
+	defaultRetryPolicy     RetryPolicy
+	operationRetryPolicies map[string]RetryPolicy
+
+	pageTokens *pageTokenSigner
+
+	cacheMu sync.Mutex
+	caches  map[*Cache]struct{}
 }
 
-func (ds *DynaSession) Table(tableName string) *DynaTable {
-	return &DynaTable{session: ds, tableName: tableName}
+// Table returns a table with the given name, configured using the table options provided
+func (ds *DynaSession) Table(tableName string, options ...TableOption) *DynaTable {
+	tableOptions := NewTableOptions(options...)
+
+	dt := &DynaTable{
+		session:      ds,
+		tableName:    tableName,
+		indexes:      tableOptions.indexes,
+		attributes:   tableOptions.attributes,
+		limiters:     newTableLimiters(tableOptions),
+		interceptors: tableOptions.interceptors,
+	}
+
+	if tableOptions.singleflight {
+		dt.flights = &flightGroup{}
+	}
+
+	return dt
 }
 
 // New construct a DynamoDB backed store with default session / service
@@ -20,9 +47,7 @@ func New(cfgs ...*aws.Config) *DynaSession {
 	sess := session.Must(session.NewSession(cfgs...))
 	dynamoSvc := dynamodb.New(sess)
 
-	return &DynaSession{
-		dynamoSvc, defaultHooks,
-	}
+	return NewWithClient(dynamoSvc, defaultHooks)
 }
 
 // New construct a DynamoDB backed store with default session / service
@@ -32,15 +57,28 @@ func NewWithOptions(awscfg *aws.Config, options ...SessionOption) *DynaSession {
 	sess := session.Must(session.NewSession(awscfg))
 	dynamoSvc := dynamodb.New(sess)
 
-	return &DynaSession{
-		dynamoSvc,
-		sessionOptions.storeHooks,
+	return newSession(dynamoSvc, sessionOptions)
+}
+
+// NewWithClient construct a DynamoDB backed store using the service provided, the hooks supplied
+// take precedence over any provided in the options unless they are nil
+func NewWithClient(dynamoSvc *dynamodb.DynamoDB, storeHooks *StoreHooks, options ...SessionOption) *DynaSession {
+	sessionOptions := NewSessionOptions(options...)
+
+	if storeHooks != nil {
+		sessionOptions.storeHooks = storeHooks
 	}
+
+	return newSession(dynamoSvc, sessionOptions)
 }
 
-func NewWithClient(dynamoSvc *dynamodb.DynamoDB, storeHooks *StoreHooks) *DynaSession {
+func newSession(dynamoSvc *dynamodb.DynamoDB, sessionOptions *SessionOptions) *DynaSession {
 	return &DynaSession{
-		dynamoSvc,
-		storeHooks,
+		DynamoDB:               dynamoSvc,
+		storeHooks:             sessionOptions.storeHooks,
+		clock:                  sessionOptions.clock,
+		defaultRetryPolicy:     sessionOptions.retryPolicy,
+		operationRetryPolicies: sessionOptions.operationRetryPolicies,
+		pageTokens:             sessionOptions.pageTokens,
 	}
 }
@@ -0,0 +1,126 @@
+package dynastore
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// flightGroup collapses concurrent identical requests into a single request whose result is shared
+//
+// The request is sent using a context which is detached from the caller which started it, so it is only
+// cancelled once every caller waiting on the result has given up.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	val     interface{}
+	err     error
+}
+
+// do invoke the function for the key unless a call is already in flight, then wait for the result or for
+// the context of the caller to be done
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	call, ok := g.calls[key]
+	if !ok {
+		sharedCtx, cancel := context.WithCancel(detachedContext{parent: ctx})
+
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			call.val, call.err = fn(sharedCtx)
+
+			g.mu.Lock()
+			g.forget(key, call)
+			g.mu.Unlock()
+
+			cancel()
+			close(call.done)
+		}()
+	}
+
+	call.waiters++
+
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		// the last caller to give up cancels the request, later callers start a new one
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			g.forget(key, call)
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) forget(key string, call *flightCall) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// detachedContext carries the values of the parent without its deadline or cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (dc detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (dc detachedContext) Done() <-chan struct{}             { return nil }
+func (dc detachedContext) Err() error                        { return nil }
+func (dc detachedContext) Value(key interface{}) interface{} { return dc.parent.Value(key) }
+
+// flightKey the key used to collapse a read of the table, this is the operation along with the request
+// after it has passed through the interceptors. Only Get, Exists and ListPage are shared, consistent reads
+// are always sent as they must observe writes which completed before they were made.
+func (dt *DynaTable) flightKey(ctx context.Context, input interface{}) (string, bool) {
+	if dt.flights == nil {
+		return "", false
+	}
+
+	operation := OperationName(ctx)
+
+	switch v := input.(type) {
+	case *dynamodb.GetItemInput:
+		if operation != "Get" && operation != "Exists" || aws.BoolValue(v.ConsistentRead) {
+			return "", false
+		}
+	case *dynamodb.QueryInput:
+		if operation != "ListPage" || aws.BoolValue(v.ConsistentRead) {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	// map keys are sorted when encoded so identical requests have the same key
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", false
+	}
+
+	return operation + "\x00" + string(data), true
+}
@@ -0,0 +1,213 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_flightGroup(t *testing.T) {
+	t.Run("should share the result of concurrent calls", func(t *testing.T) {
+		var (
+			group   flightGroup
+			calls   int32
+			wg      sync.WaitGroup
+			release = make(chan struct{})
+		)
+
+		fn := func(ctx context.Context) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return "value", nil
+		}
+
+		results := make([]interface{}, 10)
+
+		for n := range results {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				results[n], _ = group.do(context.Background(), "key", fn)
+			}(n)
+		}
+
+		waitForWaiters(t, &group, len(results))
+		close(release)
+		wg.Wait()
+
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+
+		for n, res := range results {
+			if res != "value" {
+				t.Errorf("results[%d] = %v, want value", n, res)
+			}
+		}
+	})
+
+	t.Run("should return when the context of a caller is done", func(t *testing.T) {
+		var group flightGroup
+
+		release := make(chan struct{})
+		shared := make(chan context.Context, 1)
+
+		fn := func(ctx context.Context) (interface{}, error) {
+			shared <- ctx
+			<-release
+			return "value", nil
+		}
+
+		result := make(chan interface{})
+
+		go func() {
+			res, _ := group.do(context.Background(), "key", fn)
+			result <- res
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		errs := make(chan error)
+
+		go func() {
+			_, err := group.do(ctx, "key", fn)
+			errs <- err
+		}()
+
+		waitForWaiters(t, &group, 2)
+		cancel()
+
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("do() error = %v, want context canceled", err)
+		}
+
+		if err := (<-shared).Err(); err != nil {
+			t.Errorf("shared context error = %v, want nil", err)
+		}
+
+		close(release)
+
+		if res := <-result; res != "value" {
+			t.Errorf("do() = %v, want value", res)
+		}
+	})
+
+	t.Run("should cancel the request once every caller has returned", func(t *testing.T) {
+		var group flightGroup
+
+		cancelled := make(chan error, 1)
+
+		fn := func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil, ctx.Err()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := group.do(ctx, "key", fn); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("do() error = %v, want deadline exceeded", err)
+		}
+
+		if err := <-cancelled; !errors.Is(err, context.Canceled) {
+			t.Errorf("shared context error = %v, want context canceled", err)
+		}
+	})
+}
+
+func TestDynaTable_GetWithSingleflight(t *testing.T) {
+	var requests, intercepted int32
+
+	release := make(chan struct{})
+
+	svc := newTestClient(t, func(target string, body map[string]interface{}) interface{} {
+		atomic.AddInt32(&requests, 1)
+		<-release
+
+		return map[string]interface{}{"Item": map[string]interface{}{
+			"id":      map[string]string{"S": "agent"},
+			"name":    map[string]string{"S": "key"},
+			"version": map[string]string{"N": "1"},
+		}}
+	})
+
+	countInterceptor := func(ctx context.Context, op *Operation, next Invoker) (interface{}, error) {
+		atomic.AddInt32(&intercepted, 1)
+
+		return next(ctx, op)
+	}
+
+	tbl := NewWithClient(svc, defaultHooks).Table("testing", TableWithInterceptors(countInterceptor), TableWithSingleflight())
+
+	var wg sync.WaitGroup
+
+	keys := make([]*KVPair, 10)
+
+	for n := range keys {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			var err error
+
+			keys[n], err = tbl.GetWithContext(context.Background(), "agent", "key")
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}(n)
+	}
+
+	waitForWaiters(t, tbl.flights, len(keys))
+	close(release)
+	wg.Wait()
+
+	// the interceptors are invoked for each caller while only one request is sent
+	if intercepted != int32(len(keys)) || requests != 1 {
+		t.Errorf("intercepted = %d, requests = %d, want %d and 1", intercepted, requests, len(keys))
+	}
+
+	// each caller is returned a separate record
+	if keys[0] == keys[1] {
+		t.Error("Get() returned the same record to more than one caller")
+	}
+
+	// consistent reads are not shared
+	_, err := tbl.GetWithContext(context.Background(), "agent", "key", func(opts *ReadOptions) { opts.consistent = true })
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+// waitForWaiters wait until the given number of callers are waiting on a call in the group
+func waitForWaiters(t *testing.T, group *flightGroup, waiters int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		group.mu.Lock()
+
+		var ready bool
+		for _, call := range group.calls {
+			ready = ready || call.waiters == waiters
+		}
+
+		group.mu.Unlock()
+
+		if ready {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for %d waiters", waiters)
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
 )
@@ -15,65 +14,100 @@ type contextKey int
 
 const (
 	OperationNameKey contextKey = 1 + iota
+	PartitionKeyKey
 
 	listDefaultTimeout = time.Second * 10
 )
 
 type DynaTable struct {
-	session   *DynaSession
-	tableName string
+	session      *DynaSession
+	tableName    string
+	indexes      map[string]*index
+	attributes   AttributeNames
+	limiters     *tableLimiters
+	interceptors []Interceptor
+	flights      *flightGroup
 }
 
 func (dt *DynaTable) GetTableName() string {
 	return dt.tableName
 }
 
-func (dt *DynaTable) Partition(partition string) Partition {
-	return &DynaPartition{session: dt.session, table: dt, partition: partition}
+// now the current time from the session clock, used for all TTL and expiry computations
+func (dt *DynaTable) now() time.Time {
+	return dt.session.clock.Now()
+}
+
+// Partition returns a partition of the table with the given name, configured using the partition options provided
+func (dt *DynaTable) Partition(partition string, options ...PartitionOption) Partition {
+	partitionOptions := NewPartitionOptions(options...)
+
+	if partitionOptions.cache != nil {
+		dt.session.registerCache(partitionOptions.cache)
+	}
+
+	return &DynaPartition{session: dt.session, table: dt, partition: partition, cache: partitionOptions.cache}
 }
 
 // Put a value at the specified key
 func (dt *DynaTable) PutWithContext(ctx context.Context, partitionKey, hashKey string, options ...WriteOption) error {
+	_, err := dt.PutKVWithContext(ctx, partitionKey, hashKey, options...)
+	return err
+}
+
+// PutKVWithContext put a value at the specified key returning the resulting record, including the new version
+func (dt *DynaTable) PutKVWithContext(ctx context.Context, partitionKey, hashKey string, options ...WriteOption) (_ *KVPair, err error) {
 	writeOptions := NewWriteOptions(options...)
 
-	ctx = setOperationName(ctx, "Put")
+	ctx, done := dt.session.startOperation(ctx, "Put", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
+
+	err = dt.validateIndexFields(writeOptions)
+	if err != nil {
+		return nil, err
+	}
 
-	update, err := buildUpdate(writeOptions)
+	update, err := buildUpdate(&dt.attributes, writeOptions, dt.now())
 	if err != nil {
-		return fmt.Errorf("failed to build update: %w", err)
+		return nil, fmt.Errorf("failed to build update: %w", err)
 	}
 
 	expr, err := dexp.NewBuilder().WithUpdate(update).Build()
 	if err != nil {
-		return fmt.Errorf("failed to build update expression: %w", err)
+		return nil, fmt.Errorf("failed to build update expression: %w", err)
 	}
 
 	updateItem := &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(dt.GetTableName()),
-		Key:                       buildKeys(partitionKey, hashKey),
+		Key:                       dt.attributes.buildKeys(partitionKey, hashKey),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
 		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
 	}
 
-	ctx = dt.session.storeHooks.RequestBuilt(ctx, updateItem)
+	res, err := sendTable(ctx, dt, updateItem, dt.session.UpdateItemWithContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update item: %w", err)
+	}
 
-	_, err = dt.session.UpdateItemWithContext(ctx, updateItem)
+	item, err := dt.attributes.decodeItem(res.Attributes)
 	if err != nil {
-		return fmt.Errorf("failed to update item: %w", err)
+		return nil, fmt.Errorf("failed to decode item: %w", err)
 	}
 
-	return nil
+	return item, nil
 }
 
 // GetWithContext a value given its key
 //
 // This operation uses the DynamoDB get operation which doesn't support index read options
-func (dt *DynaTable) GetWithContext(ctx context.Context, partitionKey, sortKey string, options ...ReadOption) (*KVPair, error) {
+func (dt *DynaTable) GetWithContext(ctx context.Context, partitionKey, sortKey string, options ...ReadOption) (_ *KVPair, err error) {
 	readOptions := NewReadOptions(options...)
 
-	ctx = setOperationName(ctx, "Get")
+	ctx, done := dt.session.startOperation(ctx, "Get", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
 
 	if readOptions.hasIndex() {
 		return nil, ErrIndexNotSupported
@@ -88,11 +122,11 @@ func (dt *DynaTable) GetWithContext(ctx context.Context, partitionKey, sortKey s
 	}
 
 	// is the item expired?
-	if isItemExpired(res.Item) {
+	if !readOptions.includeExpired && dt.attributes.isItemExpired(res.Item, dt.now()) {
 		return nil, ErrKeyNotFound
 	}
 
-	item, err := DecodeItem(res.Item)
+	item, err := dt.attributes.decodeItem(res.Item)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode item: %w", err)
 	}
@@ -103,24 +137,24 @@ func (dt *DynaTable) GetWithContext(ctx context.Context, partitionKey, sortKey s
 // ExistsWithContext if a sort key exists in the store
 //
 // This operation uses the DynamoDB get operation which doesn't support index read options
-func (dt *DynaTable) ExistsWithContext(ctx context.Context, partitionKey, sortKey string, options ...ReadOption) (bool, error) {
+func (dt *DynaTable) ExistsWithContext(ctx context.Context, partitionKey, sortKey string, options ...ReadOption) (_ bool, err error) {
 	readOptions := NewReadOptions(options...)
 
-	ctx = setOperationName(ctx, "Exists")
+	ctx, done := dt.session.startOperation(ctx, "Exists", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
 
 	if readOptions.hasIndex() {
 		return false, ErrIndexNotSupported
 	}
 
 	getItem := &dynamodb.GetItemInput{
-		TableName:      aws.String(dt.GetTableName()),
-		Key:            buildKeys(partitionKey, sortKey),
-		ConsistentRead: aws.Bool(readOptions.consistent),
+		TableName:              aws.String(dt.GetTableName()),
+		Key:                    dt.attributes.buildKeys(partitionKey, sortKey),
+		ConsistentRead:         aws.Bool(readOptions.consistent),
+		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
 	}
 
-	ctx = dt.session.storeHooks.RequestBuilt(ctx, getItem)
-
-	res, err := dt.session.GetItemWithContext(ctx, getItem)
+	res, err := sendTable(ctx, dt, getItem, dt.session.GetItemWithContext)
 	if err != nil {
 		return false, fmt.Errorf("failed to get item: %w", err)
 	}
@@ -130,7 +164,7 @@ func (dt *DynaTable) ExistsWithContext(ctx context.Context, partitionKey, sortKe
 	}
 
 	// is the item expired?
-	if isItemExpired(res.Item) {
+	if !readOptions.includeExpired && dt.attributes.isItemExpired(res.Item, dt.now()) {
 		return false, nil
 	}
 
@@ -139,30 +173,55 @@ func (dt *DynaTable) ExistsWithContext(ctx context.Context, partitionKey, sortKe
 
 // DeleteWithContext the value at the specified key
 func (dt *DynaTable) DeleteWithContext(ctx context.Context, partitionKey, sortKey string) error {
-	ctx = setOperationName(ctx, "Delete")
+	_, _, err := dt.DeleteKVWithContext(ctx, partitionKey, sortKey)
+	return err
+}
+
+// DeleteKVWithContext delete the value at the specified key returning whether a record existed and the
+// record which was removed
+//
+// Records which have expired, but haven't been removed by DynamoDB yet, are reported as not existing.
+func (dt *DynaTable) DeleteKVWithContext(ctx context.Context, partitionKey, sortKey string) (_ bool, _ *KVPair, err error) {
+	ctx, done := dt.session.startOperation(ctx, "Delete", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
 
 	deleteItem := &dynamodb.DeleteItemInput{
-		TableName: aws.String(dt.GetTableName()),
-		Key:       buildKeys(partitionKey, sortKey),
+		TableName:              aws.String(dt.GetTableName()),
+		Key:                    dt.attributes.buildKeys(partitionKey, sortKey),
+		ReturnValues:           aws.String(dynamodb.ReturnValueAllOld),
+		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
 	}
 
-	ctx = dt.session.storeHooks.RequestBuilt(ctx, deleteItem)
+	res, err := sendTable(ctx, dt, deleteItem, dt.session.DeleteItemWithContext)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to delete item: %w", err)
+	}
+
+	if len(res.Attributes) == 0 || dt.attributes.isItemExpired(res.Attributes, dt.now()) {
+		return false, nil, nil
+	}
 
-	_, err := dt.session.DeleteItemWithContext(ctx, deleteItem)
+	item, err := dt.attributes.decodeItem(res.Attributes)
 	if err != nil {
-		return fmt.Errorf("failed to delete item: %w", err)
+		return false, nil, fmt.Errorf("failed to decode item: %w", err)
 	}
 
-	return nil
+	return true, item, nil
 }
 
 // ListPageWithContext the content of a given prefix
-func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, prefix string, options ...ReadOption) (*KVPairPage, error) {
+func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, prefix string, options ...ReadOption) (_ *KVPairPage, err error) {
 	readOptions := NewReadOptions(options...)
 
-	ctx = setOperationName(ctx, "ListPage")
+	ctx, done := dt.session.startOperation(ctx, "ListPage", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
 
-	knames := resolveKeyAttributes(readOptions)
+	err = dt.resolveIndex(readOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	knames := resolveKeyAttributes(&dt.attributes, readOptions)
 
 	key := dexp.Key(knames.partitionKey).Equal(dexp.Value(partitionKey))
 
@@ -170,7 +229,14 @@ func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, pref
 		key = key.And(dexp.Key(knames.sortKey).BeginsWith(prefix))
 	}
 
-	expr, err := dexp.NewBuilder().WithKeyCondition(key).Build()
+	builder := dexp.NewBuilder().WithKeyCondition(key)
+
+	// expired records are filtered by DynamoDB so they don't count towards the page
+	if !readOptions.includeExpired {
+		builder = builder.WithFilter(dt.attributes.notExpiredCondition(dt.now()))
+	}
+
+	expr, err := builder.Build()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build exp: %w", err)
 	}
@@ -178,11 +244,13 @@ func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, pref
 	query := &dynamodb.QueryInput{
 		TableName:                 aws.String(dt.GetTableName()),
 		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		ConsistentRead:            aws.Bool(readOptions.consistent),
 		Limit:                     readOptions.limit,
 		ScanIndexForward:          aws.Bool(readOptions.scanIndexForward),
+		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
 	}
 
 	if readOptions.index != nil {
@@ -193,17 +261,15 @@ func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, pref
 
 	// avoid either a nil or empty value
 	if startKey := aws.StringValue(readOptions.startKey); startKey != "" {
-		decodedKey, err = decompressAndDecodeKey(startKey)
+		decodedKey, err = dt.decodePageToken(listPageScope(partitionKey, prefix, readOptions), startKey)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decompress key: %w", err)
+			return nil, fmt.Errorf("failed to decode key: %w", err)
 		}
 
 		query.ExclusiveStartKey = decodedKey
 	}
 
-	ctx = dt.session.storeHooks.RequestBuilt(ctx, query)
-
-	res, err := dt.session.QueryWithContext(ctx, query)
+	res, err := sendTable(ctx, dt, query, dt.session.QueryWithContext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run query: %w", err)
 	}
@@ -213,7 +279,7 @@ func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, pref
 	var val *KVPair
 
 	for n, item := range res.Items {
-		val, err = DecodeItem(item)
+		val, err = dt.attributes.decodeItem(item)
 		if err != nil {
 			return nil, fmt.Errorf("failed to run decode item: %w", err)
 		}
@@ -224,9 +290,9 @@ func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, pref
 	page := &KVPairPage{Keys: results}
 
 	if len(res.LastEvaluatedKey) != 0 {
-		page.LastKey, err = compressAndEncodeKey(res.LastEvaluatedKey)
+		page.LastKey, err = dt.encodePageToken(listPageScope(partitionKey, prefix, readOptions), res.LastEvaluatedKey)
 		if err != nil {
-			return nil, fmt.Errorf("failed to compress key: %w", err)
+			return nil, fmt.Errorf("failed to encode key: %w", err)
 		}
 	}
 
@@ -234,15 +300,30 @@ func (dt *DynaTable) ListPageWithContext(ctx context.Context, partitionKey, pref
 }
 
 // AtomicPutWithContext Atomic CAS operation on a single value.
-func (dt *DynaTable) AtomicPutWithContext(ctx context.Context, partitionKey, sortKey string, options ...WriteOption) (bool, *KVPair, error) {
-	writeOptions := NewWriteOptions(options...)
+//
+// If the condition fails a *ConflictError is returned wrapping ErrKeyExists or ErrKeyModified, this
+// carries the current record so callers can resolve the conflict without another read.
+func (dt *DynaTable) AtomicPutWithContext(ctx context.Context, partitionKey, sortKey string, options ...WriteOption) (_ bool, _ *KVPair, err error) {
+	ctx, done := dt.session.startOperation(ctx, "AtomicPut", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
+
+	return dt.atomicPut(ctx, partitionKey, sortKey, NewWriteOptions(options...))
+}
+
+// atomicPut the conditional write performed by AtomicPut, this is also used by Update so the write is
+// reported as part of the operation which called it
+func (dt *DynaTable) atomicPut(ctx context.Context, partitionKey, sortKey string, writeOptions *WriteOptions) (bool, *KVPair, error) {
+	err := dt.validateIndexFields(writeOptions)
+	if err != nil {
+		return false, nil, err
+	}
 
-	update, err := buildUpdate(writeOptions)
+	update, err := buildUpdate(&dt.attributes, writeOptions, dt.now())
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to build update: %w", err)
 	}
 
-	condition := updateWithConditions(writeOptions.previous)
+	condition := updateWithConditions(&dt.attributes, writeOptions.previous, dt.now())
 
 	expr, err := dexp.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
 	if err != nil {
@@ -251,30 +332,29 @@ func (dt *DynaTable) AtomicPutWithContext(ctx context.Context, partitionKey, sor
 
 	updateItem := &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(dt.GetTableName()),
-		Key:                       buildKeys(partitionKey, sortKey),
+		Key:                       dt.attributes.buildKeys(partitionKey, sortKey),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
 		ConditionExpression:       expr.Condition(),
 		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
-	}
+		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
 
-	ctx = dt.session.storeHooks.RequestBuilt(setOperationName(ctx, "AtomicPut"), updateItem)
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
 
-	res, err := dt.session.UpdateItemWithContext(ctx, updateItem)
+	res, err := sendTable(ctx, dt, updateItem, dt.session.UpdateItemWithContext)
 	if err != nil {
-		if awsErr, ok := err.(awserr.Error); ok {
-			if awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
-				if writeOptions.previous == nil {
-					return false, nil, ErrKeyExists
-				}
-				return false, nil, ErrKeyModified
+		if current, ok := conditionFailed(err); ok {
+			if writeOptions.previous == nil {
+				return false, nil, dt.newConflictError(ErrKeyExists, current)
 			}
+			return false, nil, dt.newConflictError(ErrKeyModified, current)
 		}
 		return false, nil, err
 	}
 
-	item, err := DecodeItem(res.Attributes)
+	item, err := dt.attributes.decodeItem(res.Attributes)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to decode item: %w", err)
 	}
@@ -282,26 +362,25 @@ func (dt *DynaTable) AtomicPutWithContext(ctx context.Context, partitionKey, sor
 	return true, item, nil
 }
 
-// AtomicDeleteWithContext delete of a single value
+// AtomicDeleteWithContext delete of a single value using a single conditional delete
 //
 // This supports two different operations:
 // * if previous is supplied assert it exists with the version supplied
-// * if previous is nil then assert that the key doesn't exist
+// * if previous is nil then assert it exists with any version
 //
-// FIXME: should the second case just return false, nil?
-func (dt *DynaTable) AtomicDeleteWithContext(ctx context.Context, partitionKey, sortKey string, previous *KVPair) (bool, error) {
-	ctx = setOperationName(ctx, "AtomicDelete")
-
-	getRes, err := dt.getKey(ctx, partitionKey, sortKey, NewReadOptions())
-	if err != nil {
-		return false, err
-	}
-
-	if previous == nil && getRes.Item != nil && !isItemExpired(getRes.Item) {
-		return false, ErrKeyExists
-	}
+// Note: prior versions returned ErrKeyExists when previous was nil and never deleted a record which existed,
+// a nil previous now deletes the record at any version.
+//
+// The outcomes are:
+// * deleted, returns true with no error
+// * not found, returns ErrKeyNotFound
+// * expired, the record exists but its TTL has passed, returns ErrKeyExpired
+// * version mismatch, returns a *ConflictError wrapping ErrKeyModified which carries the current record
+func (dt *DynaTable) AtomicDeleteWithContext(ctx context.Context, partitionKey, sortKey string, previous *KVPair) (_ bool, err error) {
+	ctx, done := dt.session.startOperation(ctx, "AtomicDelete", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
 
-	cond := dexp.Name("version").Equal(dexp.Value(previous.Version))
+	cond := existingWithConditions(&dt.attributes, previous, dt.now())
 
 	expr, err := dexp.NewBuilder().WithCondition(cond).Build()
 	if err != nil {
@@ -310,20 +389,19 @@ func (dt *DynaTable) AtomicDeleteWithContext(ctx context.Context, partitionKey,
 
 	req := &dynamodb.DeleteItemInput{
 		TableName:                 aws.String(dt.GetTableName()),
-		Key:                       buildKeys(partitionKey, sortKey),
+		Key:                       dt.attributes.buildKeys(partitionKey, sortKey),
 		ConditionExpression:       expr.Condition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
-	}
+		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
 
-	ctx = dt.session.storeHooks.RequestBuilt(ctx, req)
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
 
-	_, err = dt.session.DeleteItemWithContext(ctx, req)
+	_, err = sendTable(ctx, dt, req, dt.session.DeleteItemWithContext)
 	if err != nil {
-		if awsErr, ok := err.(awserr.Error); ok {
-			if awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
-				return false, ErrKeyNotFound
-			}
+		if current, ok := conditionFailed(err); ok {
+			return false, dt.existingConditionError(current)
 		}
 		return false, fmt.Errorf("failed to delete item: %w", err)
 	}
@@ -331,40 +409,107 @@ func (dt *DynaTable) AtomicDeleteWithContext(ctx context.Context, partitionKey,
 	return true, nil
 }
 
+// TouchWithContext update the time to live (TTL) of an existing value without rewriting its payload or fields, the
+// version is incremented as with any other write.
+//
+// If previous is supplied the record must be at the same version, the errors returned match AtomicDeleteWithContext.
+func (dt *DynaTable) TouchWithContext(ctx context.Context, partitionKey, sortKey string, ttl time.Duration, previous *KVPair) (_ *KVPair, err error) {
+	ctx, done := dt.session.startOperation(ctx, "Touch", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
+
+	update, err := buildUpdate(&dt.attributes, NewWriteOptions(WriteWithTTL(ttl)), dt.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update: %w", err)
+	}
+
+	cond := existingWithConditions(&dt.attributes, previous, dt.now())
+
+	expr, err := dexp.NewBuilder().WithUpdate(update).WithCondition(cond).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update expression: %w", err)
+	}
+
+	updateItem := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(dt.GetTableName()),
+		Key:                       dt.attributes.buildKeys(partitionKey, sortKey),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
+
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+
+	res, err := sendTable(ctx, dt, updateItem, dt.session.UpdateItemWithContext)
+	if err != nil {
+		if current, ok := conditionFailed(err); ok {
+			return nil, dt.existingConditionError(current)
+		}
+		return nil, fmt.Errorf("failed to update item: %w", err)
+	}
+
+	item, err := dt.attributes.decodeItem(res.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode item: %w", err)
+	}
+
+	return item, nil
+}
+
 func (dt *DynaTable) getKey(ctx context.Context, partitionKey, sortKey string, options *ReadOptions) (*dynamodb.GetItemOutput, error) {
 	getItem := &dynamodb.GetItemInput{
-		TableName:      aws.String(dt.GetTableName()),
-		ConsistentRead: aws.Bool(options.consistent),
-		Key:            buildKeys(partitionKey, sortKey),
+		TableName:              aws.String(dt.GetTableName()),
+		ConsistentRead:         aws.Bool(options.consistent),
+		Key:                    dt.attributes.buildKeys(partitionKey, sortKey),
+		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
 	}
 
-	ctx = dt.session.storeHooks.RequestBuilt(ctx, getItem)
-
-	return dt.session.GetItemWithContext(ctx, getItem)
+	return sendTable(ctx, dt, getItem, dt.session.GetItemWithContext)
 }
 
-func buildUpdate(options *WriteOptions) (dexp.UpdateBuilder, error) {
-	update := dexp.Add(dexp.Name("version"), dexp.Value(1))
+func buildUpdate(attributes *AttributeNames, options *WriteOptions, now time.Time) (dexp.UpdateBuilder, error) {
+	update := dexp.Add(dexp.Name(attributes.Version), dexp.Value(1))
 
 	// if a value assigned
 	if options.value != nil {
-		update = update.Set(dexp.Name("payload"), dexp.Value(options.value))
+		update = update.Set(dexp.Name(attributes.Payload), dexp.Value(options.value))
 	}
 
 	if options.fields != nil {
 		for k, v := range options.fields {
-			if isReservedField(k) {
+			if attributes.isReserved(k) {
 				return update, ErrReservedField
 			}
 			update = update.Set(dexp.Name(k), dexp.Value(v))
 		}
 	}
 
+	for _, k := range options.removeFields {
+		if attributes.isReserved(k) {
+			return update, ErrReservedField
+		}
+		if _, ok := options.fields[k]; ok {
+			return update, fmt.Errorf("field %s: %w", k, ErrFieldConflict)
+		}
+		update = update.Remove(dexp.Name(k))
+	}
+
 	// if a TTL assigned
 	if options.ttl != nil {
-		ttlVal := time.Now().Add(*options.ttl).Unix()
+		ttlVal := now.Add(*options.ttl).Unix()
+
+		update = update.Set(dexp.Name(attributes.Expires), dexp.Value(ttlVal))
+	}
 
-		update = update.Set(dexp.Name("expires"), dexp.Value(ttlVal))
+	// if an absolute expiry assigned
+	if options.expiresAt != nil {
+		update = update.Set(dexp.Name(attributes.Expires), dexp.Value(options.expiresAt.Unix()))
+	}
+
+	if options.removeExpires {
+		update = update.Remove(dexp.Name(attributes.Expires))
 	}
 
 	return update, nil
@@ -377,10 +522,10 @@ type keyAttributes struct {
 
 // resolveKeyAttributes using the read options resolve the name of the keys to use in the query
 // including index options.
-func resolveKeyAttributes(readOptions *ReadOptions) *keyAttributes {
+func resolveKeyAttributes(attributes *AttributeNames, readOptions *ReadOptions) *keyAttributes {
 	knames := &keyAttributes{
-		partitionKey: DefaultPartitionKeyAttribute,
-		sortKey:      DefaultSortKeyAttribute,
+		partitionKey: attributes.PartitionKey,
+		sortKey:      attributes.SortKey,
 	}
 
 	if readOptions.index != nil {
@@ -394,27 +539,32 @@ func resolveKeyAttributes(readOptions *ReadOptions) *keyAttributes {
 	return knames
 }
 
-func buildKeys(partition, key string) map[string]*dynamodb.AttributeValue {
-	return map[string]*dynamodb.AttributeValue{
-		DefaultPartitionKeyAttribute: {S: aws.String(partition)},
-		DefaultSortKeyAttribute:      {S: aws.String(key)},
+// existingWithConditions the record must exist, not be expired, and if a previous record is supplied be at the same version
+func existingWithConditions(attributes *AttributeNames, previous *KVPair, now time.Time) dexp.ConditionBuilder {
+	// "attribute_exists(id) AND ( attribute_not_exists(expires) OR expires > :timeNow ) [AND version = :lastRevision]"
+	cond := dexp.And(
+		dexp.AttributeExists(dexp.Name(attributes.PartitionKey)),
+		attributes.notExpiredCondition(now),
+	)
+
+	if previous != nil {
+		cond = cond.And(dexp.Name(attributes.Version).Equal(dexp.Value(previous.Version)))
 	}
+
+	return cond
 }
 
-func updateWithConditions(previous *KVPair) dexp.ConditionBuilder {
+func updateWithConditions(attributes *AttributeNames, previous *KVPair, now time.Time) dexp.ConditionBuilder {
 	if previous != nil {
-		// "version = :lastRevision AND ( attribute_not_exists(expires) OR (attribute_exists(expires) AND expires > :timeNow) )"
+		// "version = :lastRevision AND ( attribute_not_exists(expires) OR expires > :timeNow )"
 
 		// the previous kv is in the DB and is at the expected revision, also if it has a TTL set it is NOT expired.
-		checkExpires := dexp.Or(
-			dexp.AttributeNotExists(dexp.Name("expires")),
-			dexp.Name("expires").GreaterThanEqual(dexp.Value(time.Now().Unix())),
-		)
+		checkExpires := attributes.notExpiredCondition(now)
 
 		//
 		// if there is a previous provided then we override the create check
 		//
-		checkVersion := dexp.Name("version").Equal(dexp.Value(previous.Version))
+		checkVersion := dexp.Name(attributes.Version).Equal(dexp.Value(previous.Version))
 
 		return dexp.And(checkVersion, checkExpires)
 	}
@@ -423,17 +573,15 @@ func updateWithConditions(previous *KVPair) dexp.ConditionBuilder {
 	// assign the create check to ensure record doesn't exist which isn't expired
 	//
 
-	// "(attribute_not_exists(id) AND attribute_not_exists(#name)) OR (attribute_exists(expires) AND expires < :timeNow)"
+	// "(attribute_not_exists(id) AND attribute_not_exists(#name)) OR (attribute_exists(expires) AND expires <= :timeNow)"
 
-	// the previous kv is in the DB and is at the expected revision, also if it has a TTL set it is NOT expired.
-	checkExpires := dexp.And(
-		dexp.AttributeNotExists(dexp.Name("expires")),
-		dexp.Name("expires").LessThan(dexp.Value(time.Now().Unix())),
-	)
-	// if the record exists and is NOT expired
+	// the previous kv is in the DB but it has a TTL set which has expired.
+	checkExpires := attributes.expiredCondition(now)
+
+	// if the record doesn't exist
 	checkExists := dexp.And(
-		dexp.AttributeNotExists(dexp.Name(DefaultPartitionKeyAttribute)),
-		dexp.AttributeNotExists(dexp.Name(DefaultSortKeyAttribute)),
+		dexp.AttributeNotExists(dexp.Name(attributes.PartitionKey)),
+		dexp.AttributeNotExists(dexp.Name(attributes.SortKey)),
 	)
 
 	return dexp.Or(checkExists, checkExpires)
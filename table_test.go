@@ -0,0 +1,117 @@
+package dynastore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	dexp "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func Test_buildUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      []WriteOption
+		wantSet      []string
+		wantRemove   []string
+		wantNotNames []string
+		wantErr      error
+	}{
+		{
+			name:    "should set payload and expires",
+			options: []WriteOption{WriteWithString("value"), WriteWithTTL(60)},
+			wantSet: []string{"payload", "expires"},
+		},
+		{
+			name:    "should set absolute expiry",
+			options: []WriteOption{WriteWithTTL(60), WriteWithExpiresAt(time.Unix(1600000000, 0))},
+			wantSet: []string{"expires"},
+		},
+		{
+			name:         "should patch fields without payload",
+			options:      []WriteOption{WriteWithString("value"), WriteWithoutPayload(), WriteWithFields(map[string]string{"created": "20200103T1100Z"})},
+			wantSet:      []string{"created"},
+			wantNotNames: []string{"payload"},
+		},
+		{
+			name:       "should remove fields and expires",
+			options:    []WriteOption{WriteWithTTL(60), WriteWithRemoveExpires(), WriteWithRemoveFields("pk1", "sk1")},
+			wantRemove: []string{"pk1", "sk1", "expires"},
+		},
+		{
+			name:    "should reject removing reserved field",
+			options: []WriteOption{WriteWithRemoveFields("payload")},
+			wantErr: ErrReservedField,
+		},
+		{
+			name:    "should reject assigning and removing the same field",
+			options: []WriteOption{WriteWithFields(map[string]string{"pk1": "wolfeidau"}), WriteWithRemoveFields("pk1")},
+			wantErr: ErrFieldConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes := DefaultAttributeNames()
+
+			update, err := buildUpdate(&attributes, NewWriteOptions(tt.options...), time.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("buildUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			expr, err := dexp.NewBuilder().WithUpdate(update).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			// map the expression placeholders back to the attribute names
+			clause := aliasUpdateClauses(expr)
+
+			for _, name := range tt.wantSet {
+				if !strings.Contains(clause["SET"], name) {
+					t.Errorf("buildUpdate() SET = %q, want %s", clause["SET"], name)
+				}
+			}
+			for _, name := range tt.wantRemove {
+				if !strings.Contains(clause["REMOVE"], name) {
+					t.Errorf("buildUpdate() REMOVE = %q, want %s", clause["REMOVE"], name)
+				}
+			}
+			for _, name := range tt.wantNotNames {
+				for _, v := range clause {
+					if strings.Contains(v, name) {
+						t.Errorf("buildUpdate() = %q, want no %s", v, name)
+					}
+				}
+			}
+		})
+	}
+}
+
+// aliasUpdateClauses split the update expression into its clauses with the attribute names substituted
+func aliasUpdateClauses(expr dexp.Expression) map[string]string {
+	update := *expr.Update()
+
+	for alias, name := range expr.Names() {
+		update = strings.ReplaceAll(update, alias, *name)
+	}
+
+	clauses := map[string]string{}
+
+	var current string
+
+	for _, token := range strings.Fields(update) {
+		switch token {
+		case "SET", "REMOVE", "ADD", "DELETE":
+			current = token
+		default:
+			clauses[current] += token + " "
+		}
+	}
+
+	return clauses
+}
@@ -0,0 +1,128 @@
+package dynastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultUpdateMaxAttempts = 5
+	defaultUpdateBaseDelay   = 20 * time.Millisecond
+	defaultUpdateMaxDelay    = time.Second
+)
+
+// UpdateFunc is passed the current record, which is nil if it doesn't exist, and returns the write options
+// used to update it. Returning an error aborts the update, this error is returned by UpdateWithContext.
+//
+// This may be called more than once if the record is modified concurrently so should not have side effects.
+type UpdateFunc func(current *KVPair) ([]WriteOption, error)
+
+// UpdateWithContext perform an optimistic read, modify and write of a single value.
+//
+// The current record is read and passed to the function provided, the options it returns are then written
+// using an AtomicPut which asserts the record hasn't changed. If another writer wins the mutation is applied
+// again to the latest record, up to a maximum number of attempts with a jittered backoff between them.
+func (dt *DynaTable) UpdateWithContext(ctx context.Context, partitionKey, sortKey string, fn UpdateFunc, options ...UpdateOption) (_ *KVPair, err error) {
+	updateOptions := NewUpdateOptions(options...)
+
+	ctx, done := dt.session.startOperation(ctx, "Update", dt.GetTableName(), partitionKey)
+	defer func() { done(err) }()
+
+	current, err := dt.getCurrent(ctx, partitionKey, sortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if current == nil && !updateOptions.create {
+			return nil, ErrKeyNotFound
+		}
+
+		writeOptions, err := fn(current)
+		if err != nil {
+			return nil, err
+		}
+
+		// a nil previous asserts the record doesn't exist
+		writeOptions = append(writeOptions, WriteWithPreviousKV(current))
+
+		_, kv, err := dt.atomicPut(ctx, partitionKey, sortKey, NewWriteOptions(writeOptions...))
+		if err == nil {
+			return kv, nil
+		}
+
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) {
+			return nil, err
+		}
+
+		if attempt >= updateOptions.maxAttempts {
+			return nil, fmt.Errorf("update failed after %d attempts: %w", attempt, err)
+		}
+
+		err = sleepWithContext(ctx, backoffDelay(updateOptions.baseDelay, updateOptions.maxDelay, attempt))
+		if err != nil {
+			return nil, err
+		}
+
+		current = conflict.Current
+
+		// the current record isn't returned if it was deleted, expired, or the backend doesn't support it
+		if current == nil {
+			current, err = dt.getCurrent(ctx, partitionKey, sortKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// getCurrent consistent read of a record returning nil if it doesn't exist or has expired
+func (dt *DynaTable) getCurrent(ctx context.Context, partitionKey, sortKey string) (*KVPair, error) {
+	res, err := dt.getKey(ctx, partitionKey, sortKey, &ReadOptions{consistent: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get by key: %w", err)
+	}
+
+	if res.Item == nil || dt.attributes.isItemExpired(res.Item, dt.now()) {
+		return nil, nil
+	}
+
+	item, err := dt.attributes.decodeItem(res.Item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode item: %w", err)
+	}
+
+	return item, nil
+}
+
+// backoffDelay exponential backoff with full jitter for the given attempt, starting at 1
+func backoffDelay(baseDelay, maxDelay time.Duration, attempt int) time.Duration {
+	delay := maxDelay
+
+	// avoid overflow by capping the shift
+	if attempt < 32 && baseDelay<<uint(attempt-1) < maxDelay {
+		delay = baseDelay << uint(attempt-1)
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay)))
+}
+
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
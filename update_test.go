@@ -0,0 +1,31 @@
+package dynastore
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_backoffDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseDelay time.Duration
+		maxDelay  time.Duration
+		attempt   int
+		wantMax   time.Duration
+	}{
+		{name: "should start at base delay", baseDelay: 10 * time.Millisecond, maxDelay: time.Second, attempt: 1, wantMax: 10 * time.Millisecond},
+		{name: "should double each attempt", baseDelay: 10 * time.Millisecond, maxDelay: time.Second, attempt: 4, wantMax: 80 * time.Millisecond},
+		{name: "should cap at max delay", baseDelay: 10 * time.Millisecond, maxDelay: time.Second, attempt: 100, wantMax: time.Second},
+		{name: "should handle zero delay", attempt: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := backoffDelay(tt.baseDelay, tt.maxDelay, tt.attempt)
+				if got < 0 || (got >= tt.wantMax && tt.wantMax != 0) || (tt.wantMax == 0 && got != 0) {
+					t.Fatalf("backoffDelay() = %v, want [0, %v)", got, tt.wantMax)
+				}
+			}
+		})
+	}
+}
@@ -2,10 +2,7 @@ package dynastore
 
 import (
 	"context"
-	"strconv"
-	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
@@ -15,44 +12,11 @@ const (
 	int64bits = 64
 )
 
-// DecodeItem decode a DDB attribute value into a KVPair
+// DecodeItem decode a DDB attribute value into a KVPair, this uses the default attribute names
 func DecodeItem(item map[string]*dynamodb.AttributeValue) (*KVPair, error) {
-	kv := new(KVPair)
+	attributes := DefaultAttributeNames()
 
-	err := dynamodbattribute.UnmarshalMap(item, kv)
-	if err != nil {
-		return nil, err
-	}
-
-	if val, ok := item["payload"]; ok {
-		kv.value = val
-	}
-
-	kv.fields = make(map[string]*dynamodb.AttributeValue)
-
-	for k, v := range item {
-		if !isReservedField(k) {
-			kv.fields[k] = v
-		}
-	}
-
-	return kv, nil
-}
-
-func isReservedField(s string) bool {
-	_, ok := reservedFields[s]
-	return ok
-}
-
-func isItemExpired(item map[string]*dynamodb.AttributeValue) bool {
-	var ttl int64
-
-	if v, ok := item["expires"]; ok {
-		ttl, _ = strconv.ParseInt(aws.StringValue(v.N), base10, int64bits)
-		return time.Unix(ttl, 0).Before(time.Now())
-	}
-
-	return false
+	return attributes.decodeItem(item)
 }
 
 // MarshalStruct this helper method marshals a struct into an *dynamodb.AttributeValue which contains a map
@@ -81,3 +45,14 @@ func OperationName(ctx context.Context) string {
 func setOperationName(ctx context.Context, name string) context.Context {
 	return context.WithValue(ctx, OperationNameKey, name)
 }
+
+// PartitionKey extracts the partition key of the operation being handled in the given
+// context. If it is not known, it returns ("").
+func PartitionKey(ctx context.Context) string {
+	key, _ := ctx.Value(PartitionKeyKey).(string)
+	return key
+}
+
+func setPartitionKey(ctx context.Context, partitionKey string) context.Context {
+	return context.WithValue(ctx, PartitionKeyKey, partitionKey)
+}